@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// IMPORTANT: Run "make generate" to regenerate code after modifying this file
+
+package v0alpha0
+
+import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +kubebuilder:object:root=true
+// XProxyConfig configures the Envoy proxies deployed for Gateways. It is referenced from
+// GatewayClass.spec.parametersRef, and from Gateway.spec.infrastructure.parametersRef to
+// override the GatewayClass settings for a single Gateway.
+type XProxyConfig struct {
+	metav1.TypeMeta `json:",inline"`
+	// metadata is a standard object metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+	// spec defines the desired configuration of the proxies.
+	// +required
+	Spec XProxyConfigSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+// XProxyConfigList contains a list of XProxyConfig.
+type XProxyConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	// metadata is a standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []XProxyConfig `json:"items"`
+}
+
+// XProxyConfigSpec defines the configuration of the proxies. Unset fields fall back to the
+// GatewayClass configuration, and then to the controller defaults.
+type XProxyConfigSpec struct {
+	// image is the Envoy image of the proxy. Defaults to the controller's --envoy-image.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	Image *string `json:"image,omitempty"`
+	// replicas is the number of proxy replicas. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+	// resources are the compute resources of the proxy container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// logLevel is the Envoy log level. Defaults to trace.
+	// +optional
+	LogLevel *ProxyLogLevel `json:"logLevel,omitempty"`
+	// serviceType is the type of the Service exposing the proxy. Defaults to LoadBalancer.
+	// +optional
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	ServiceType *corev1.ServiceType `json:"serviceType,omitempty"`
+	// autoscaling makes a HorizontalPodAutoscaler manage the number of proxy replicas, in
+	// which case replicas is ignored.
+	// +optional
+	Autoscaling *ProxyAutoscaling `json:"autoscaling,omitempty"`
+	// podDisruptionBudget configures a PodDisruptionBudget for the proxy pods.
+	// +optional
+	PodDisruptionBudget *ProxyPodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+	// mergeGateways serves all Gateways of the GatewayClass from one shared proxy Deployment
+	// and Service in the controller namespace, instead of deploying proxies per Gateway. Gateways
+	// then cannot listen on the same port. It is only read from GatewayClass parameters, and the
+	// infrastructure parameters of the individual Gateways are ignored. Gateways that set
+	// spec.addresses are not accepted. Defaults to false.
+	// +optional
+	MergeGateways *bool `json:"mergeGateways,omitempty"`
+}
+
+// ProxyAutoscaling configures the HorizontalPodAutoscaler of the proxies.
+type ProxyAutoscaling struct {
+	// minReplicas is the lower limit for the number of replicas. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// maxReplicas is the upper limit for the number of replicas.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// metrics are the metrics used to compute the desired number of replicas. Defaults to
+	// a target average CPU utilization of 80%.
+	// +optional
+	// +listType=atomic
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+	// behavior configures the scaling behavior in the up and down directions.
+	// +optional
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+}
+
+// ProxyPodDisruptionBudget configures the PodDisruptionBudget of the proxies. Exactly one of
+// minAvailable and maxUnavailable must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.minAvailable) != has(self.maxUnavailable)",message="exactly one of minAvailable and maxUnavailable must be set"
+type ProxyPodDisruptionBudget struct {
+	// minAvailable is the number or percentage of proxy pods that must remain available
+	// during a voluntary disruption.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// maxUnavailable is the number or percentage of proxy pods that may be unavailable
+	// during a voluntary disruption.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// ProxyLogLevel is an Envoy log level.
+// +kubebuilder:validation:Enum=trace;debug;info;warning;error;critical;off
+type ProxyLogLevel string
+
+const (
+	ProxyLogLevelTrace    ProxyLogLevel = "trace"
+	ProxyLogLevelDebug    ProxyLogLevel = "debug"
+	ProxyLogLevelInfo     ProxyLogLevel = "info"
+	ProxyLogLevelWarning  ProxyLogLevel = "warning"
+	ProxyLogLevelError    ProxyLogLevel = "error"
+	ProxyLogLevelCritical ProxyLogLevel = "critical"
+	ProxyLogLevelOff      ProxyLogLevel = "off"
+)
@@ -29,6 +29,7 @@ import (
 type AinetworkingV0alpha0Interface interface {
 	RESTClient() rest.Interface
 	XBackendDestinationsGetter
+	XProxyConfigsGetter
 }
 
 // AinetworkingV0alpha0Client is used to interact with features provided by the ainetworking.prototype.x-k8s.io group.
@@ -40,6 +41,10 @@ func (c *AinetworkingV0alpha0Client) XBackendDestinations(namespace string) XBac
 	return newXBackendDestinations(c, namespace)
 }
 
+func (c *AinetworkingV0alpha0Client) XProxyConfigs(namespace string) XProxyConfigInterface {
+	return newXProxyConfigs(c, namespace)
+}
+
 // NewForConfig creates a new AinetworkingV0alpha0Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
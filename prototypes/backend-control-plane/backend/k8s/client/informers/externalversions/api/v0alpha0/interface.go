@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// XBackendDestinations returns a XBackendDestinationInformer.
 	XBackendDestinations() XBackendDestinationInformer
+	// XProxyConfigs returns a XProxyConfigInformer.
+	XProxyConfigs() XProxyConfigInformer
 }
 
 type version struct {
@@ -43,3 +45,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) XBackendDestinations() XBackendDestinationInformer {
 	return &xBackendDestinationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// XProxyConfigs returns a XProxyConfigInformer.
+func (v *version) XProxyConfigs() XProxyConfigInformer {
+	return &xProxyConfigInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
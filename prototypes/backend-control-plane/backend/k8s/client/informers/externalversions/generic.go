@@ -55,6 +55,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=ainetworking.prototype.x-k8s.io, Version=v0alpha0
 	case v0alpha0.SchemeGroupVersion.WithResource("xbackenddestinations"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ainetworking().V0alpha0().XBackendDestinations().Informer()}, nil
+	case v0alpha0.SchemeGroupVersion.WithResource("xproxyconfigs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ainetworking().V0alpha0().XProxyConfigs().Informer()}, nil
 
 	}
 
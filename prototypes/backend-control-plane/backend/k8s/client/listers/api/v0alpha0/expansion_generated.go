@@ -25,3 +25,11 @@ type XBackendDestinationListerExpansion interface{}
 // XBackendDestinationNamespaceListerExpansion allows custom methods to be added to
 // XBackendDestinationNamespaceLister.
 type XBackendDestinationNamespaceListerExpansion interface{}
+
+// XProxyConfigListerExpansion allows custom methods to be added to
+// XProxyConfigLister.
+type XProxyConfigListerExpansion interface{}
+
+// XProxyConfigNamespaceListerExpansion allows custom methods to be added to
+// XProxyConfigNamespaceLister.
+type XProxyConfigNamespaceListerExpansion interface{}
@@ -19,23 +19,35 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/dynamic"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayclient "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 	gatewayinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
 
 	aigatewayclient "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/k8s/client/clientset/versioned"
 	aigatewayinformers "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/k8s/client/informers/externalversions"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
 	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/controllers"
+	envoydeployer "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/deployer/envoy"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/metrics"
 )
 
 var (
@@ -43,6 +55,16 @@ var (
 	kubeconfig      string
 	resyncPeriod    time.Duration
 	envoyProxyImage string
+	metricsAddr     string
+	controllerName  string
+	watchNamespaces string
+
+	orphanGCInterval time.Duration
+	orphanGCDryRun   bool
+
+	leaderElect             bool
+	leaderElectionNamespace string
+	leaderElectionID        string
 )
 
 func init() {
@@ -50,6 +72,14 @@ func init() {
 	flag.StringVar(&apiServerURL, "apiserver-url", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 	flag.StringVar(&envoyProxyImage, "envoy-image", "", "The Envoy proxy image to use for deployed proxies.")
 	flag.DurationVar(&resyncPeriod, "resync-period", 0, "Resync period for informers. Typically set to zero")
+	flag.StringVar(&metricsAddr, "metrics-bind-address", ":9090", "The address to serve Prometheus metrics and the /healthz and /readyz endpoints on.")
+	flag.StringVar(&controllerName, "controller-name", constants.EnvoyControllerName, "The controllerName of the GatewayClasses to manage. Run one controller per name to serve separate sets of GatewayClasses.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "", "Comma-separated namespaces to watch Gateways, routes and their references in, instead of all namespaces. "+constants.AIGatewaySystemNamespace+" is always watched. Namespaces and GatewayClasses are still watched cluster-wide.")
+	flag.DurationVar(&orphanGCInterval, "orphan-gc-interval", 10*time.Minute, "How often to delete managed proxy resources whose Gateway is gone or uses another GatewayClass. The first collection runs on startup. Zero disables it.")
+	flag.BoolVar(&orphanGCDryRun, "orphan-gc-dry-run", false, "Only log the orphaned proxy resources instead of deleting them.")
+	flag.BoolVar(&leaderElect, "leader-elect", true, "Elect a leader among replicas to deploy Gateway infrastructure and write statuses. All replicas serve xDS.")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "", "Namespace of the leader election Lease. Defaults to $POD_NAMESPACE, or "+constants.AIGatewaySystemNamespace+" if unset.")
+	flag.StringVar(&leaderElectionID, "leader-election-id", "wg-ai-gateway-controller", "Name of the leader election Lease.")
 }
 
 func main() {
@@ -63,6 +93,10 @@ func main() {
 	if envoyProxyImage == "" {
 		fatal(&logger, nil, "--envoy-image cannot be empty")
 	}
+	// The controller name also labels the deployed proxy resources.
+	if errs := validation.IsValidLabelValue(envoydeployer.ManagedLabelValue(gatewayv1.GatewayController(controllerName))); len(errs) > 0 {
+		fatal(&logger, nil, fmt.Sprintf("invalid --controller-name %q: %s", controllerName, strings.Join(errs, ", ")))
+	}
 
 	config, err := buildKubeConfig(kubeconfig, apiServerURL)
 	if err != nil {
@@ -87,22 +121,49 @@ func main() {
 		fatal(&logger, err, "unable to create ai gateway client")
 	}
 
-	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, resyncPeriod)
-	gatewayInformerFactory := gatewayinformers.NewSharedInformerFactory(gatewayClient, resyncPeriod)
-	aigatewayInformerFactory := aigatewayinformers.NewSharedInformerFactory(aigatewayClient, resyncPeriod)
+	newInformerFactories := func(namespace string) controllers.InformerFactories {
+		return controllers.InformerFactories{
+			Namespace: namespace,
+			Kube:      kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, resyncPeriod, kubeinformers.WithNamespace(namespace)),
+			Gateway:   gatewayinformers.NewSharedInformerFactoryWithOptions(gatewayClient, resyncPeriod, gatewayinformers.WithNamespace(namespace)),
+			AIGateway: aigatewayinformers.NewSharedInformerFactoryWithOptions(aigatewayClient, resyncPeriod, aigatewayinformers.WithNamespace(namespace)),
+		}
+	}
+	clusterInformers := newInformerFactories(metav1.NamespaceAll)
+	var namespacedInformers []controllers.InformerFactories
+	if watchNamespaces != "" {
+		// The shared proxies of merged Gateways and the GatewayClass parameters live in the
+		// system namespace.
+		namespaces := sets.New(constants.AIGatewaySystemNamespace)
+		for _, namespace := range strings.Split(watchNamespaces, ",") {
+			namespace = strings.TrimSpace(namespace)
+			if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+				fatal(&logger, nil, fmt.Sprintf("invalid namespace %q in --watch-namespaces: %s", namespace, strings.Join(errs, ", ")))
+			}
+			namespaces.Insert(namespace)
+		}
+		for _, namespace := range sets.List(namespaces) {
+			namespacedInformers = append(namespacedInformers, newInformerFactories(namespace))
+		}
+		logger.Info("Watching namespaces", "namespaces", sets.List(namespaces))
+	}
 
 	// Pass the envoy xDS server to the AI Gateway controller
 	// so that the latter can notify the former about config changes.
 	controller, err := controllers.NewController(
 		ctx,
 		envoyProxyImage,
+		controllers.Options{
+			ControllerName:   gatewayv1.GatewayController(controllerName),
+			OrphanGCInterval: orphanGCInterval,
+			OrphanGCDryRun:   orphanGCDryRun,
+		},
 		kubeClient,
 		dynamicClient,
 		gatewayClient,
 		aigatewayClient,
-		kubeInformerFactory,
-		gatewayInformerFactory,
-		aigatewayInformerFactory,
+		clusterInformers,
+		namespacedInformers,
 	)
 	if err != nil {
 		fatal(&logger, err, "unable to create controller")
@@ -110,9 +171,23 @@ func main() {
 
 	// NOTE: Ensure all informers are registered before starting them
 	// all below
-	kubeInformerFactory.Start(ctx.Done())
-	gatewayInformerFactory.Start(ctx.Done())
-	aigatewayInformerFactory.Start(ctx.Done())
+	for _, factories := range append([]controllers.InformerFactories{clusterInformers}, namespacedInformers...) {
+		factories.Kube.Start(ctx.Done())
+		factories.Gateway.Start(ctx.Done())
+		factories.AIGateway.Start(ctx.Done())
+	}
+
+	go func() {
+		if err := metrics.Serve(ctx, metricsAddr, map[string]metrics.ReadinessCheck{"controller": controller.Ready}); err != nil {
+			fatal(&logger, err, "unable to serve metrics")
+		}
+	}()
+
+	if leaderElect {
+		go runLeaderElection(ctx, &logger, kubeClient, controller)
+	} else {
+		go controller.Lead(ctx)
+	}
 
 	// Start the controller
 	if err := controller.Run(ctx); err != nil {
@@ -120,6 +195,59 @@ func main() {
 	}
 }
 
+// runLeaderElection lets the controller lead while this replica holds the Lease. A replica that
+// loses the Lease exits, so that it restarts as a follower.
+func runLeaderElection(ctx context.Context, logger *klog.Logger, kubeClient kubernetes.Interface, controller controllers.Controller) {
+	namespace := leaderElectionNamespace
+	if namespace == "" {
+		namespace = os.Getenv("POD_NAMESPACE")
+	}
+	if namespace == "" {
+		namespace = constants.AIGatewaySystemNamespace
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		fatal(logger, err, "unable to determine hostname for leader election")
+	}
+	identity := hostname + "_" + string(uuid.NewUUID())
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      leaderElectionID,
+			Namespace: namespace,
+		},
+		Client: kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   15 * time.Second,
+		RenewDeadline:   10 * time.Second,
+		RetryPeriod:     2 * time.Second,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: controller.Lead,
+			OnStoppedLeading: func() {
+				if ctx.Err() != nil {
+					// Shutting down, the Lease was released on purpose.
+					return
+				}
+				fatal(logger, nil, "lost leader election lease")
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					logger.Info("Following leader", "leader", leader)
+				}
+			},
+		},
+		Name: leaderElectionID,
+	})
+}
+
 func fatal(l *klog.Logger, err error, msg string) {
 	if l == nil {
 		klog.Error(err, msg)
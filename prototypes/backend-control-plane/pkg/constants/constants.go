@@ -12,6 +12,21 @@ const (
 	EnvoyControllerName = "sigs.k8s.io/wg-ai-gateway-envoy-controller"
 
 	ManagedGatewayLabel = "aigateway.networking.k8s.io/managed"
+	// GatewayFinalizer holds the deletion of a Gateway until its infrastructure and xDS snapshot
+	// are cleaned up.
+	GatewayFinalizer = "aigateway.networking.k8s.io/finalizer"
+
+	// DeployerAnnotation selects how the proxies of a Gateway are provisioned, on the Gateway or
+	// on its GatewayClass, the Gateway taking precedence. It is DeployerManaged by default.
+	DeployerAnnotation = "aigateway.networking.k8s.io/deployer"
+	// DeployerManaged has the controller deploy the proxies of the Gateway.
+	DeployerManaged = "managed"
+	// DeployerUnmanaged leaves the proxies of the Gateway to be provisioned by someone else,
+	// e.g. a platform Helm chart. The controller only serves them their configuration.
+	DeployerUnmanaged = "unmanaged"
+	// ProxyServiceAnnotation names the Service, in the namespace of a Gateway with unmanaged
+	// proxies, whose addresses are reported in the Gateway status.
+	ProxyServiceAnnotation = "aigateway.networking.k8s.io/proxy-service"
 
 	// EnvoyImage is the default Envoy proxy image to use.
 	EnvoyImage = "envoyproxy/envoy:v1.37-latest"
@@ -8,6 +8,9 @@ const (
 
 	// ListenerNameFormat is the format string for Envoy listener names, becoming `listener-<port>`.
 	ListenerNameFormat = "listener-%d"
+	// MergedListenerNameFormat is the format string for the Envoy listener names of Gateways that share
+	// their proxies, becoming `<namespace>-<gateway-name>-listener-<port>`.
+	MergedListenerNameFormat = "%s-%s-listener-%d"
 	// RouteNameFormat is the format string for Envoy route configuration names, becoming `route-<port>`.
 	RouteNameFormat = "route-%d"
 	// EnvoyRouteNameFormat is the format string for individual Envoy route names within a RouteConfiguration,
@@ -17,4 +20,24 @@ const (
 	VHostNameFormat = "%s-vh-%d-%s"
 	// ClusterNameFormat is the format string for Envoy cluster names, becoming `<namespace>-<backend-name>-<port>`.
 	ClusterNameFormat = "%s-%s-%d"
+	// SessionNameFormat is the format string for the default session persistence cookie or header name,
+	// becoming `<namespace>-<httproute-name>-rule<rule-index>-session`.
+	SessionNameFormat = "%s-%s-rule%d-session"
+	// InvalidBackendClusterName is the name of a cluster that is never defined. Weighted clusters send the
+	// share of traffic for invalid backendRefs to it so that Envoy answers those requests with a 500, which
+	// requires route configurations not to validate their clusters.
+	InvalidBackendClusterName = "invalid-backend-ref"
+
+	// StatefulSessionFilterName is the name of the Envoy HTTP filter that implements session persistence.
+	StatefulSessionFilterName = "envoy.filters.http.stateful_session"
+	// CookieSessionStateName is the name of the cookie-based session state extension.
+	CookieSessionStateName = "envoy.http.stateful_session.cookie"
+	// HeaderSessionStateName is the name of the header-based session state extension.
+	HeaderSessionStateName = "envoy.http.stateful_session.header"
+	// BackendPathRewriteFilterName is the name of the Lua HTTP filter that rewrites the path of requests
+	// sent to a backendRef with a URLRewrite path modifier. It is a no-op unless the weighted cluster
+	// picked for the request enables it.
+	BackendPathRewriteFilterName = "envoy.filters.http.lua.backend_path_rewrite"
+	// BackendPathRewriteSourceName is the name of the Lua source code of the backend path rewrite filter.
+	BackendPathRewriteSourceName = "backend-path-rewrite"
 )
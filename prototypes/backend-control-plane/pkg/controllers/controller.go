@@ -20,6 +20,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -29,22 +32,25 @@ import (
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
-	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	appv1listers "k8s.io/client-go/listers/apps/v1"
+	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	policyv1listers "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayclientset "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
-	gatewayinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
 	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
 
 	aigatewayclientset "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/k8s/client/clientset/versioned"
-	aigatewayinformers "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/k8s/client/informers/externalversions"
 	aigatewaylisters "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/k8s/client/listers/api/v0alpha0"
 	envoydeployer "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/deployer/envoy"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/metrics"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/references"
 	envoytranslator "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/translator/envoy"
 	envoycontrolplane "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/xds/envoy"
 )
@@ -52,10 +58,32 @@ import (
 const (
 	numWorkers   = 2
 	workInterval = 1 * time.Second
+	// pendingRecheckInterval is how often a Gateway whose proxies are pending is checked again.
+	pendingRecheckInterval = 5 * time.Second
 )
 
 type Controller interface {
+	// Run starts the xDS server and the workers that translate Gateways. It blocks until the
+	// context is done.
 	Run(context.Context) error
+	// Lead makes this replica responsible for deploying Gateway infrastructure and writing
+	// statuses. It must be called once this replica holds the leader lease, or right away
+	// when leader election is disabled.
+	Lead(context.Context)
+	// Ready returns an error until the informer caches have synced and the xDS server is serving.
+	Ready() error
+}
+
+// Options configures the optional behavior of the controller.
+type Options struct {
+	// ControllerName is the controllerName of the GatewayClasses whose Gateways this controller
+	// manages.
+	ControllerName gatewayv1.GatewayController
+	// OrphanGCInterval is how often the leader deletes the managed resources that no Gateway
+	// owns anymore. Zero disables the collection.
+	OrphanGCInterval time.Duration
+	// OrphanGCDryRun only logs the orphaned resources instead of deleting them.
+	OrphanGCDryRun bool
 }
 
 type coreResources struct {
@@ -69,6 +97,8 @@ type coreResources struct {
 	serviceAccountLister corev1listers.ServiceAccountLister
 	deploymentLister     appv1listers.DeploymentLister
 	serviceLister        corev1listers.ServiceLister
+	hpaLister            autoscalingv2listers.HorizontalPodAutoscalerLister
+	pdbLister            policyv1listers.PodDisruptionBudgetLister
 }
 
 type gatewayResources struct {
@@ -82,7 +112,8 @@ type gatewayResources struct {
 type aiGatewayResources struct {
 	client aigatewayclientset.Interface
 
-	backendLister aigatewaylisters.XBackendDestinationLister
+	backendLister     aigatewaylisters.XBackendDestinationLister
+	proxyConfigLister aigatewaylisters.XProxyConfigLister
 }
 
 type controller struct {
@@ -91,95 +122,238 @@ type controller struct {
 	aigateway *aiGatewayResources
 
 	gatewayqueue    workqueue.TypedRateLimitingInterface[string]
+	endpointqueue   workqueue.TypedRateLimitingInterface[string]
 	envoyProxyImage string
-	syncers         []cache.InformerSynced
-	controlplane    envoycontrolplane.ControlPlane
-	translator      envoytranslator.Translator
-	stop            <-chan struct{}
+	options         Options
+	// namespaces are the namespaces whose resources are watched, or just NamespaceAll.
+	namespaces   []string
+	syncers      []cache.InformerSynced
+	controlplane envoycontrolplane.ControlPlane
+	translator   envoytranslator.Translator
+	tracker      *references.Tracker
+	recorder     record.EventRecorder
+	leader       atomic.Bool
+	synced       atomic.Bool
+	// awaitingAck holds the keys of the Gateways whose status waits on their proxies.
+	awaitingAck sync.Map
+	// mergedClasses holds the name of the GatewayClass whose shared proxies last served each
+	// Gateway, by Gateway key.
+	mergedClasses sync.Map
+	stop          <-chan struct{}
 }
 
+// NewController returns a controller that watches cluster-scoped resources through
+// clusterInformers, and namespaced resources through namespacedInformers, one per watched
+// namespace. When namespacedInformers is empty, namespaced resources are watched in all
+// namespaces through clusterInformers.
 func NewController(
 	ctx context.Context,
 	envoyProxyImage string,
+	options Options,
 	kubeClient kubernetes.Interface,
 	dynamicClient dynamic.Interface,
 	gatewayClient gatewayclientset.Interface,
 	aigatewayClient aigatewayclientset.Interface,
-	kubeInformerFactory kubeinformers.SharedInformerFactory,
-	gatewayInformerFactory gatewayinformers.SharedInformerFactory,
-	aigatewayInformerFactory aigatewayinformers.SharedInformerFactory,
+	clusterInformers InformerFactories,
+	namespacedInformers []InformerFactories,
 ) (Controller, error) {
+	if len(namespacedInformers) == 0 {
+		namespacedInformers = []InformerFactories{clusterInformers}
+	}
+	namespaced := func(informerFor func(InformerFactories) cache.SharedIndexInformer) *namespacedInformer {
+		return newNamespacedInformer(namespacedInformers, informerFor)
+	}
+
+	namespaceInformer := clusterInformers.Kube.Core().V1().Namespaces().Informer()
+	gatewayClassInformer := clusterInformers.Gateway.Gateway().V1().GatewayClasses().Informer()
+	serviceInformer := namespaced(func(f InformerFactories) cache.SharedIndexInformer {
+		return f.Kube.Core().V1().Services().Informer()
+	})
+	secretInformer := namespaced(func(f InformerFactories) cache.SharedIndexInformer {
+		return f.Kube.Core().V1().Secrets().Informer()
+	})
+	configMapInformer := namespaced(func(f InformerFactories) cache.SharedIndexInformer {
+		return f.Kube.Core().V1().ConfigMaps().Informer()
+	})
+	serviceAccountInformer := namespaced(func(f InformerFactories) cache.SharedIndexInformer {
+		return f.Kube.Core().V1().ServiceAccounts().Informer()
+	})
+	endpointSliceInformer := namespaced(func(f InformerFactories) cache.SharedIndexInformer {
+		return f.Kube.Discovery().V1().EndpointSlices().Informer()
+	})
+	deploymentInformer := namespaced(func(f InformerFactories) cache.SharedIndexInformer {
+		return f.Kube.Apps().V1().Deployments().Informer()
+	})
+	hpaInformer := namespaced(func(f InformerFactories) cache.SharedIndexInformer {
+		return f.Kube.Autoscaling().V2().HorizontalPodAutoscalers().Informer()
+	})
+	pdbInformer := namespaced(func(f InformerFactories) cache.SharedIndexInformer {
+		return f.Kube.Policy().V1().PodDisruptionBudgets().Informer()
+	})
+	gatewayInformer := namespaced(func(f InformerFactories) cache.SharedIndexInformer {
+		return f.Gateway.Gateway().V1().Gateways().Informer()
+	})
+	httpRouteInformer := namespaced(func(f InformerFactories) cache.SharedIndexInformer {
+		return f.Gateway.Gateway().V1().HTTPRoutes().Informer()
+	})
+	backendInformer := namespaced(func(f InformerFactories) cache.SharedIndexInformer {
+		return f.AIGateway.Ainetworking().V0alpha0().XBackendDestinations().Informer()
+	})
+	proxyConfigInformer := namespaced(func(f InformerFactories) cache.SharedIndexInformer {
+		return f.AIGateway.Ainetworking().V0alpha0().XProxyConfigs().Informer()
+	})
+
+	namespaces := make([]string, 0, len(namespacedInformers))
+	for _, f := range namespacedInformers {
+		namespaces = append(namespaces, f.Namespace)
+	}
+
+	nsLister := corev1listers.NewNamespaceLister(namespaceInformer.GetIndexer())
+	serviceLister := corev1listers.NewServiceLister(serviceInformer.GetIndexer())
+	secretLister := corev1listers.NewSecretLister(secretInformer.GetIndexer())
+	gatewayLister := gatewaylisters.NewGatewayLister(gatewayInformer.GetIndexer())
+	httpRouteLister := gatewaylisters.NewHTTPRouteLister(httpRouteInformer.GetIndexer())
+	backendLister := aigatewaylisters.NewXBackendDestinationLister(backendInformer.GetIndexer())
+
+	tracker := references.NewTracker()
 	c := &controller{
 		core: &coreResources{
 			client:               kubeClient,
 			dynamicClient:        dynamicClient,
-			nsLister:             kubeInformerFactory.Core().V1().Namespaces().Lister(),
-			svcLister:            kubeInformerFactory.Core().V1().Services().Lister(),
-			secretLister:         kubeInformerFactory.Core().V1().Secrets().Lister(),
-			configMapLister:      kubeInformerFactory.Core().V1().ConfigMaps().Lister(),
-			serviceAccountLister: kubeInformerFactory.Core().V1().ServiceAccounts().Lister(),
-			deploymentLister:     kubeInformerFactory.Apps().V1().Deployments().Lister(),
-			serviceLister:        kubeInformerFactory.Core().V1().Services().Lister(),
+			nsLister:             nsLister,
+			svcLister:            serviceLister,
+			secretLister:         secretLister,
+			configMapLister:      corev1listers.NewConfigMapLister(configMapInformer.GetIndexer()),
+			serviceAccountLister: corev1listers.NewServiceAccountLister(serviceAccountInformer.GetIndexer()),
+			deploymentLister:     appv1listers.NewDeploymentLister(deploymentInformer.GetIndexer()),
+			serviceLister:        serviceLister,
+			hpaLister:            autoscalingv2listers.NewHorizontalPodAutoscalerLister(hpaInformer.GetIndexer()),
+			pdbLister:            policyv1listers.NewPodDisruptionBudgetLister(pdbInformer.GetIndexer()),
 		},
 		gateway: &gatewayResources{
 			client:             gatewayClient,
-			gatewayClassLister: gatewayInformerFactory.Gateway().V1().GatewayClasses().Lister(),
-			gatewayLister:      gatewayInformerFactory.Gateway().V1().Gateways().Lister(),
-			httpRouteLister:    gatewayInformerFactory.Gateway().V1().HTTPRoutes().Lister(),
+			gatewayClassLister: gatewaylisters.NewGatewayClassLister(gatewayClassInformer.GetIndexer()),
+			gatewayLister:      gatewayLister,
+			httpRouteLister:    httpRouteLister,
 		},
 		aigateway: &aiGatewayResources{
-			client:        aigatewayClient,
-			backendLister: aigatewayInformerFactory.Ainetworking().V0alpha0().XBackendDestinations().Lister(),
+			client:            aigatewayClient,
+			backendLister:     backendLister,
+			proxyConfigLister: aigatewaylisters.NewXProxyConfigLister(proxyConfigInformer.GetIndexer()),
 		},
 		stop:            ctx.Done(),
 		envoyProxyImage: envoyProxyImage,
+		options:         options,
+		namespaces:      namespaces,
 		gatewayqueue: workqueue.NewTypedRateLimitingQueueWithConfig(
 			workqueue.DefaultTypedControllerRateLimiter[string](),
 			workqueue.TypedRateLimitingQueueConfig[string]{Name: "gateway"},
 		),
-		controlplane: envoycontrolplane.NewControlPlane(ctx),
+		endpointqueue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "endpoints"},
+		),
+		tracker:  tracker,
+		recorder: newEventRecorder(ctx, kubeClient, options.ControllerName),
 		translator: envoytranslator.New(
+			options.ControllerName,
 			kubeClient,
 			gatewayClient,
-			kubeInformerFactory.Core().V1().Namespaces().Lister(),
-			kubeInformerFactory.Core().V1().Services().Lister(),
-			kubeInformerFactory.Core().V1().Secrets().Lister(),
-			kubeInformerFactory.Discovery().V1().EndpointSlices().Lister(),
-			gatewayInformerFactory.Gateway().V1().Gateways().Lister(),
-			gatewayInformerFactory.Gateway().V1().HTTPRoutes().Lister(),
-			aigatewayInformerFactory.Ainetworking().V0alpha0().XBackendDestinations().Lister(),
+			nsLister,
+			serviceLister,
+			secretLister,
+			discoverylisters.NewEndpointSliceLister(endpointSliceInformer.GetIndexer()),
+			gatewayLister,
+			httpRouteLister,
+			backendLister,
+			tracker,
+			namespaces,
 		),
 	}
 
+	c.controlplane = envoycontrolplane.NewControlPlane(ctx, c.onProxyAck, c.onProxyNack)
+
 	c.syncers = []cache.InformerSynced{
-		kubeInformerFactory.Core().V1().Namespaces().Informer().HasSynced,
-		kubeInformerFactory.Core().V1().Services().Informer().HasSynced,
-		kubeInformerFactory.Core().V1().Secrets().Informer().HasSynced,
-		gatewayInformerFactory.Gateway().V1().GatewayClasses().Informer().HasSynced,
-		gatewayInformerFactory.Gateway().V1().Gateways().Informer().HasSynced,
-		gatewayInformerFactory.Gateway().V1().HTTPRoutes().Informer().HasSynced,
-		aigatewayInformerFactory.Ainetworking().V0alpha0().XBackendDestinations().Informer().HasSynced,
+		namespaceInformer.HasSynced,
+		serviceInformer.HasSynced,
+		secretInformer.HasSynced,
+		endpointSliceInformer.HasSynced,
+		gatewayClassInformer.HasSynced,
+		gatewayInformer.HasSynced,
+		httpRouteInformer.HasSynced,
+		backendInformer.HasSynced,
+		proxyConfigInformer.HasSynced,
 	}
 
 	// Set up event handlers for Gateway API resources
-	if err := c.setupGatewayClassEventHandlers(gatewayInformerFactory.Gateway().V1().GatewayClasses()); err != nil {
+	if err := c.setupGatewayClassEventHandlers(gatewayClassInformer); err != nil {
 		return nil, fmt.Errorf("failed to setup gatewayclass event handlers: %w", err)
 	}
 
-	if err := c.setupGatewayEventHandlers(gatewayInformerFactory.Gateway().V1().Gateways()); err != nil {
+	if err := c.setupGatewayEventHandlers(gatewayInformer); err != nil {
 		return nil, fmt.Errorf("failed to setup gateway event handlers: %w", err)
 	}
 
-	if err := c.setupHTTPRouteEventHandlers(gatewayInformerFactory.Gateway().V1().HTTPRoutes()); err != nil {
+	if err := c.setupHTTPRouteEventHandlers(httpRouteInformer); err != nil {
 		return nil, fmt.Errorf("failed to setup httproute event handlers: %w", err)
 	}
 
+	// Set up event handlers for objects read during translation, so that changes to them
+	// re-enqueue the Gateways that depend on them.
+	referenceInformers := []struct {
+		name     string
+		informer eventSource
+		toRef    refFunc
+	}{
+		{"service", serviceInformer, namespacedRef(references.KindService)},
+		{"secret", secretInformer, namespacedRef(references.KindSecret)},
+		{"namespace", namespaceInformer, namespaceRef},
+		{"xbackenddestination", backendInformer, namespacedRef(references.KindBackend)},
+	}
+	for _, ri := range referenceInformers {
+		if err := c.setupReferenceEventHandlers(ri.informer, ri.toRef); err != nil {
+			return nil, fmt.Errorf("failed to setup %s event handlers: %w", ri.name, err)
+		}
+	}
+
+	// Set up event handlers for the resources deployed for Gateways, so that their status is
+	// refreshed and changes made by anyone else are reverted.
+	infraInformers := []struct {
+		name     string
+		informer eventSource
+	}{
+		{"deployment", deploymentInformer},
+		{"service", serviceInformer},
+		{"configmap", configMapInformer},
+		{"serviceaccount", serviceAccountInformer},
+		{"horizontalpodautoscaler", hpaInformer},
+		{"poddisruptionbudget", pdbInformer},
+	}
+	for _, ii := range infraInformers {
+		if err := c.setupGatewayInfraEventHandlers(ii.informer); err != nil {
+			return nil, fmt.Errorf("failed to setup gateway %s event handlers: %w", ii.name, err)
+		}
+	}
+
+	if err := c.setupProxyConfigEventHandlers(proxyConfigInformer); err != nil {
+		return nil, fmt.Errorf("failed to setup xproxyconfig event handlers: %w", err)
+	}
+
+	if err := c.setupProxyServiceEventHandlers(serviceInformer); err != nil {
+		return nil, fmt.Errorf("failed to setup proxy service event handlers: %w", err)
+	}
+
+	if err := c.setupEndpointSliceEventHandlers(endpointSliceInformer); err != nil {
+		return nil, fmt.Errorf("failed to setup endpointslice event handlers: %w", err)
+	}
+
 	return c, nil
 }
 
 func (c *controller) Run(ctx context.Context) error {
 	defer runtime.HandleCrashWithContext(ctx)
 	defer c.gatewayqueue.ShutDown()
+	defer c.endpointqueue.ShutDown()
 
 	// Note: control plane Run() is non-blocking so it's
 	// safe to run in this goroutine
@@ -190,9 +364,11 @@ func (c *controller) Run(ctx context.Context) error {
 	if ok := cache.WaitForCacheSync(ctx.Done(), c.syncers...); !ok {
 		return errors.New("failed to wait for caches to sync")
 	}
+	c.synced.Store(true)
 
 	for range numWorkers {
 		go wait.UntilWithContext(ctx, c.runWorker, workInterval)
+		go wait.UntilWithContext(ctx, c.runEndpointWorker, workInterval)
 	}
 	klog.Infof("Started %d workers", numWorkers)
 
@@ -200,6 +376,13 @@ func (c *controller) Run(ctx context.Context) error {
 	return nil
 }
 
+func (c *controller) Ready() error {
+	if !c.synced.Load() {
+		return errors.New("informer caches have not synced")
+	}
+	return c.controlplane.Ready()
+}
+
 func (c *controller) runWorker(ctx context.Context) {
 	for c.processNextWorkItem(ctx) {
 	}
@@ -214,7 +397,19 @@ func (c *controller) processNextWorkItem(ctx context.Context) bool {
 	defer c.gatewayqueue.Done(item)
 
 	// We expect strings (namespace/name) to come off the workqueue.
-	if err := c.syncHandler(ctx, item); err != nil {
+	startTime := time.Now()
+	err := c.syncHandler(ctx, item)
+	metrics.ReconcileDuration.WithLabelValues("Gateway").Observe(time.Since(startTime).Seconds())
+	if errors.Is(err, errGatewayPending) {
+		// Check again at a fixed interval, without counting it as an error. Forget keeps the
+		// failures of earlier syncs from growing the backoff of the next real error.
+		c.gatewayqueue.Forget(item)
+		c.gatewayqueue.AddAfter(item, pendingRecheckInterval)
+		klog.V(2).InfoS("Gateway proxies are pending", "key", item)
+		return true
+	}
+	if err != nil {
+		metrics.ReconcileErrors.WithLabelValues("Gateway").Inc()
 		// Put the item back on the workqueue to handle any transient errors.
 		c.gatewayqueue.AddRateLimited(item)
 		klog.ErrorS(err, "Error syncing", "key", item)
@@ -230,6 +425,11 @@ func (c *controller) processNextWorkItem(ctx context.Context) bool {
 
 // syncHandler processes a key from the workqueue and reconciles it
 func (c *controller) syncHandler(ctx context.Context, key string) error {
+	if gatewayClassName, ok := strings.CutPrefix(key, mergedClassKeyPrefix); ok {
+		logger := klog.FromContext(ctx).WithValues("gatewayclass", gatewayClassName)
+		return c.syncMergedGatewayClass(klog.NewContext(ctx, logger), gatewayClassName)
+	}
+
 	namespace, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
 		runtime.HandleError(fmt.Errorf("invalid resource key: %w", err))
@@ -242,35 +442,95 @@ func (c *controller) syncHandler(ctx context.Context, key string) error {
 	gateway, err := c.gateway.gatewayLister.Gateways(namespace).Get(name)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			logger.Info("Gateway deleted, cleaning up associated resources.")
-			return envoydeployer.DeleteGatewayInfra(ctx, c.core.client, types.NamespacedName{Namespace: namespace, Name: name})
+			// The finalizer has already cleaned up, or the Gateway was deleted before we added it
+			// and its infrastructure is garbage collected through its owner references.
+			logger.Info("Gateway deleted")
+			key := types.NamespacedName{Namespace: namespace, Name: name}
+			c.forgetGateway(key)
+			c.resyncMergedGateways(key, "")
+			return nil
 		}
 		return err
 	}
 
-	logger.Info("Syncing gateway")
+	if gateway.DeletionTimestamp != nil || !c.managesGateway(gateway) {
+		// Gateways that moved to another GatewayClass, or whose GatewayClass is gone, are torn
+		// down the same way as deleted ones.
+		return c.finalizeGateway(ctx, gateway)
+	}
 
-	deployer := envoydeployer.NewDeployer(
-		c.core.client,
-		c.core.dynamicClient,
-		gateway,
-		c.envoyProxyImage,
-		c.core.configMapLister,
-		c.core.serviceAccountLister,
-		c.core.serviceLister,
-		c.core.deploymentLister,
-	)
-	if err := deployer.Deploy(ctx); err != nil {
-		return fmt.Errorf("failed to deploy gateway infrastructure: %w", err)
+	logger.Info("Syncing gateway", "leader", c.isLeader())
+
+	if c.isLeader() {
+		gateway, err = c.ensureFinalizer(ctx, gateway)
+		if err != nil {
+			return err
+		}
 	}
 
-	logger.Info("Reconciled gateway successfully")
+	// A Gateway that moved from a merged GatewayClass must be removed from its shared proxies.
+	gwc, _, merged := c.mergedGatewayClass(gateway)
+	current := ""
+	if merged {
+		current = gwc.Name
+	}
+	c.resyncMergedGateways(types.NamespacedName{Namespace: namespace, Name: name}, current)
+	if merged {
+		// The Gateways sharing the proxies are synced together under their GatewayClass key.
+		c.enqueueMergedGateways(gwc.Name)
+		return nil
+	}
+
+	nodeID := envoydeployer.NodeIDForGateway(types.NamespacedName{Namespace: namespace, Name: name})
+	if c.isLeader() {
+		proxyConfig, err := c.proxyConfigForGateway(gateway)
+		if err != nil {
+			var invalidErr *invalidParametersError
+			if !errors.As(err, &invalidErr) {
+				return fmt.Errorf("failed to resolve gateway parameters: %w", err)
+			}
+			// The Gateway is re-enqueued once the referenced configuration changes.
+			logger.Info("Gateway has invalid parameters", "reason", invalidErr.message)
+			return c.updateGatewayNotAccepted(ctx, gateway, gatewayv1.GatewayReasonInvalidParameters, invalidErr.message)
+		}
+
+		deployer := c.newDeployer(gateway, proxyConfig)
+		err = deployer.Deploy(ctx)
+		var conflictErr *envoydeployer.ConflictError
+		if errors.As(err, &conflictErr) {
+			// The rest of the infrastructure is deployed; the conflicting objects are left to
+			// their owners.
+			logger.Info("Gateway infrastructure conflicts with unmanaged resources", "reason", conflictErr.Error())
+			c.recordDeployConflict([]*gatewayv1.Gateway{gateway}, conflictErr)
+			err = nil
+		}
+		if err != nil {
+			var addressErr *envoydeployer.AddressError
+			if !errors.As(err, &addressErr) {
+				return fmt.Errorf("failed to deploy gateway infrastructure: %w", err)
+			}
+			// Retrying does not help until the Gateway's addresses change.
+			logger.Info("Gateway has unusable addresses", "reason", addressErr.Message)
+			if addressErr.Reason == gatewayv1.GatewayReasonUnsupportedAddress {
+				return c.updateGatewayNotAccepted(ctx, gateway, addressErr.Reason, addressErr.Message)
+			}
+			return c.updateGatewayStatus(ctx, gateway, nil, metav1.ConditionFalse, string(addressErr.Reason), addressErr.Message)
+		}
+		nodeID = deployer.NodeID()
+
+		logger.Info("Reconciled gateway successfully")
+	}
 
 	// Translate Gateway to xDS resources.
-	resources, httpRouteStatuses, err := c.translator.TranslateGatewayAndReferencesToXDS(ctx, gateway)
+	translationStart := time.Now()
+	resources, listenerStatuses, httpRouteStatuses, err := c.translator.TranslateGatewayAndReferencesToXDS(ctx, gateway)
+	metrics.TranslationDuration.Observe(time.Since(translationStart).Seconds())
 	if err != nil {
-		if statusErr := c.updateGatewayStatus(ctx, gateway, metav1.ConditionFalse, "TranslationError", err.Error()); statusErr != nil {
-			logger.Error(statusErr, "failed to update gateway status with translation error")
+		if c.isLeader() {
+			c.recordTranslationError(gateway, err)
+			if statusErr := c.updateGatewayStatus(ctx, gateway, nil, metav1.ConditionFalse, "TranslationError", err.Error()); statusErr != nil {
+				logger.Error(statusErr, "failed to update gateway status with translation error")
+			}
 		}
 		return fmt.Errorf("failed to translate gateway to xDS resources: %w", err)
 	}
@@ -278,14 +538,37 @@ func (c *controller) syncHandler(ctx context.Context, key string) error {
 	logger.Info("Translated gateway to xDS resources")
 
 	// Update the xDS server with the new resources.
-	if err := c.controlplane.PushXDS(ctx, deployer.NodeID(), resources); err != nil {
+	if err := c.controlplane.PushXDS(ctx, nodeID, resources); err != nil {
 		return fmt.Errorf("failed to update xDS server: %w", err)
 	}
+	c.resyncEndpoints(types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name})
+
+	logger.Info("Updated xDS server with new resources", "nodeID", nodeID)
+
+	// Only the leader writes statuses.
+	if !c.isLeader() {
+		return nil
+	}
 
-	logger.Info("Updated xDS server with new resources", "nodeID", deployer.NodeID())
+	// The Gateway is only programmed once its proxies are available and serve its configuration.
+	fleet := c.proxyFleetFor(gateway)
+	pending, err := c.proxyPendingReason(fleet)
+	if err != nil {
+		return fmt.Errorf("failed to check gateway proxies: %w", err)
+	}
+	programmed, reason, message := metav1.ConditionTrue, "Programmed", "Gateway is programmed and ready"
+	if fleet.unmanaged {
+		message = fmt.Sprintf("Gateway is programmed and ready for unmanaged proxies with %s", unmanagedProxyIdentity(fleet))
+	}
+	if pending != "" {
+		c.awaitingAck.Store(key, struct{}{})
+		programmed, reason, message = metav1.ConditionFalse, string(gatewayv1.GatewayReasonPending), pending
+	} else {
+		c.awaitingAck.Delete(key)
+	}
 
 	// Update Gateway status to indicate successful programming
-	if err := c.updateGatewayStatus(ctx, gateway, metav1.ConditionTrue, "Programmed", "Gateway is programmed and ready"); err != nil {
+	if err := c.updateGatewayStatus(ctx, gateway, listenerStatuses, programmed, reason, message); err != nil {
 		logger.Error(err, "failed to update gateway status")
 		// Don't return error as the gateway is actually working
 	}
@@ -298,65 +581,98 @@ func (c *controller) syncHandler(ctx context.Context, key string) error {
 		}
 	}
 
+	if pending != "" {
+		return errGatewayPending
+	}
 	return nil
 }
 
-// updateGatewayStatus updates the Gateway status with the given condition.
-func (c *controller) updateGatewayStatus(ctx context.Context, gateway *gatewayv1.Gateway, status metav1.ConditionStatus, reason, message string) error {
-	// Create a copy to avoid modifying the cached object
-	gatewayCopy := gateway.DeepCopy()
-
-	// Set the Accepted condition
-	apimeta.SetStatusCondition(&gatewayCopy.Status.Conditions, metav1.Condition{
+// updateGatewayStatus updates the Gateway status with the given Programmed condition and listener statuses.
+// The Accepted condition is derived from the listener statuses, and a Gateway with no programmed listener
+// is never reported as Programmed. A nil listenerStatuses leaves the listener statuses untouched.
+func (c *controller) updateGatewayStatus(ctx context.Context, gateway *gatewayv1.Gateway, listenerStatuses []gatewayv1.ListenerStatus, status metav1.ConditionStatus, reason, message string) error {
+	acceptedCondition := metav1.Condition{
 		Type:               string(gatewayv1.GatewayConditionAccepted),
 		Status:             metav1.ConditionTrue,
-		Reason:             "Accepted",
+		Reason:             string(gatewayv1.GatewayReasonAccepted),
 		Message:            "Gateway configuration is valid",
+		ObservedGeneration: gateway.Generation,
 		LastTransitionTime: metav1.Now(),
-	})
-
-	// Set the Programmed condition
-	apimeta.SetStatusCondition(&gatewayCopy.Status.Conditions, metav1.Condition{
+	}
+	programmedCondition := metav1.Condition{
 		Type:               string(gatewayv1.GatewayConditionProgrammed),
 		Status:             status,
 		Reason:             reason,
 		Message:            message,
+		ObservedGeneration: gateway.Generation,
 		LastTransitionTime: metav1.Now(),
-	})
+	}
+
+	if listenerStatuses != nil {
+		var accepted, programmed int
+		for _, ls := range listenerStatuses {
+			if apimeta.IsStatusConditionTrue(ls.Conditions, string(gatewayv1.ListenerConditionAccepted)) {
+				accepted++
+			}
+			if apimeta.IsStatusConditionTrue(ls.Conditions, string(gatewayv1.ListenerConditionProgrammed)) {
+				programmed++
+			}
+		}
 
-	// Set the address if the gateway is programmed successfully
-	if status == metav1.ConditionTrue {
-		// Find the LoadBalancer service for this gateway and get its external IP
-		deployer := envoydeployer.NewDeployer(
-			c.core.client,
-			c.core.dynamicClient,
-			gateway,
-			"", // image not needed for getting the service
-			c.core.configMapLister,
-			c.core.serviceAccountLister,
-			c.core.serviceLister,
-			c.core.deploymentLister,
-		)
-
-		// Try to get the service and extract the LoadBalancer IP
-		serviceName := deployer.NodeID()
-		service, err := c.core.serviceLister.Services(gateway.Namespace).Get(serviceName)
-		if err == nil && service.Spec.Type == "LoadBalancer" && len(service.Status.LoadBalancer.Ingress) > 0 {
-			// Set addresses from LoadBalancer ingress
-			gatewayCopy.Status.Addresses = []gatewayv1.GatewayStatusAddress{}
-			for _, ingress := range service.Status.LoadBalancer.Ingress {
-				if ingress.IP != "" {
-					gatewayCopy.Status.Addresses = append(gatewayCopy.Status.Addresses, gatewayv1.GatewayStatusAddress{
-						Type:  &[]gatewayv1.AddressType{gatewayv1.IPAddressType}[0],
-						Value: ingress.IP,
-					})
-				}
+		if accepted < len(listenerStatuses) {
+			acceptedCondition.Reason = string(gatewayv1.GatewayReasonListenersNotValid)
+			acceptedCondition.Message = fmt.Sprintf("%d of %d listeners are not valid", len(listenerStatuses)-accepted, len(listenerStatuses))
+			if accepted == 0 {
+				acceptedCondition.Status = metav1.ConditionFalse
 			}
 		}
+
+		if programmed == 0 && programmedCondition.Status == metav1.ConditionTrue {
+			programmedCondition.Status = metav1.ConditionFalse
+			programmedCondition.Reason = string(gatewayv1.GatewayReasonInvalid)
+			programmedCondition.Message = "No listeners are programmed"
+		}
 	}
 
-	// Update the Gateway status
-	_, err := c.gateway.client.GatewayV1().Gateways(gateway.Namespace).UpdateStatus(ctx, gatewayCopy, metav1.UpdateOptions{})
+	// Report the addresses of the proxy Service, and don't report the Gateway as programmed
+	// until the addresses it requests are assigned.
+	var addresses []gatewayv1.GatewayStatusAddress
+	setAddresses := programmedCondition.Status == metav1.ConditionTrue
+	if setAddresses {
+		fleet := c.proxyFleetFor(gateway)
+		service, err := c.core.serviceLister.Services(fleet.namespace).Get(fleet.name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get gateway service: %w", err)
+		}
+
+		if service != nil {
+			addresses = envoydeployer.ServiceStatusAddresses(service)
+		}
+
+		if missing := envoydeployer.UnassignedAddresses(gateway, addresses); len(missing) > 0 {
+			programmedCondition.Status = metav1.ConditionFalse
+			programmedCondition.Reason = string(gatewayv1.GatewayReasonAddressNotAssigned)
+			programmedCondition.Message = fmt.Sprintf("Requested addresses are not assigned yet: %s", strings.Join(missing, ", "))
+		} else if len(addresses) == 0 {
+			programmedCondition.Status = metav1.ConditionFalse
+			programmedCondition.Reason = string(gatewayv1.GatewayReasonAddressNotAssigned)
+			programmedCondition.Message = fmt.Sprintf("Service %s/%s has no address assigned yet", fleet.namespace, fleet.name)
+		}
+	}
+
+	if listenerStatuses != nil {
+		c.recordListenerEvents(gateway, listenerStatuses)
+	}
+	err := c.writeGatewayStatus(ctx, gateway, func(gateway *gatewayv1.Gateway) {
+		if listenerStatuses != nil {
+			gateway.Status.Listeners = mergeListenerStatuses(gateway.Status.Listeners, listenerStatuses)
+		}
+		if setAddresses {
+			gateway.Status.Addresses = addresses
+		}
+		apimeta.SetStatusCondition(&gateway.Status.Conditions, acceptedCondition)
+		apimeta.SetStatusCondition(&gateway.Status.Conditions, programmedCondition)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to update gateway status: %w", err)
 	}
@@ -364,7 +680,35 @@ func (c *controller) updateGatewayStatus(ctx context.Context, gateway *gatewayv1
 	return nil
 }
 
-// updateHTTPRouteStatus updates the HTTPRoute status with the given parent statuses.
+// updateGatewayNotAccepted reports that the Gateway is neither accepted nor programmed for the
+// given Accepted condition reason.
+func (c *controller) updateGatewayNotAccepted(ctx context.Context, gateway *gatewayv1.Gateway, reason gatewayv1.GatewayConditionReason, message string) error {
+	err := c.writeGatewayStatus(ctx, gateway, func(gateway *gatewayv1.Gateway) {
+		apimeta.SetStatusCondition(&gateway.Status.Conditions, metav1.Condition{
+			Type:               string(gatewayv1.GatewayConditionAccepted),
+			Status:             metav1.ConditionFalse,
+			Reason:             string(reason),
+			Message:            message,
+			ObservedGeneration: gateway.Generation,
+			LastTransitionTime: metav1.Now(),
+		})
+		apimeta.SetStatusCondition(&gateway.Status.Conditions, metav1.Condition{
+			Type:               string(gatewayv1.GatewayConditionProgrammed),
+			Status:             metav1.ConditionFalse,
+			Reason:             string(gatewayv1.GatewayReasonInvalid),
+			Message:            "Gateway is not programmed because it is not accepted",
+			ObservedGeneration: gateway.Generation,
+			LastTransitionTime: metav1.Now(),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update gateway status: %w", err)
+	}
+	return nil
+}
+
+// updateHTTPRouteStatus writes our parent statuses into the HTTPRoute status, leaving the
+// entries of other controllers untouched.
 func (c *controller) updateHTTPRouteStatus(ctx context.Context, httpRouteKey types.NamespacedName, parentStatuses []gatewayv1.RouteParentStatus) error {
 	// Get the current HTTPRoute
 	httpRoute, err := c.gateway.httpRouteLister.HTTPRoutes(httpRouteKey.Namespace).Get(httpRouteKey.Name)
@@ -372,14 +716,10 @@ func (c *controller) updateHTTPRouteStatus(ctx context.Context, httpRouteKey typ
 		return fmt.Errorf("failed to get httproute: %w", err)
 	}
 
-	// Create a copy to avoid modifying the cached object
-	httpRouteCopy := httpRoute.DeepCopy()
-
-	// Set the parent statuses
-	httpRouteCopy.Status.Parents = parentStatuses
-
-	// Update the HTTPRoute status
-	_, err = c.gateway.client.GatewayV1().HTTPRoutes(httpRoute.Namespace).UpdateStatus(ctx, httpRouteCopy, metav1.UpdateOptions{})
+	c.recordRouteEvents(httpRoute, parentStatuses)
+	err = c.writeHTTPRouteStatus(ctx, httpRoute, func(httpRoute *gatewayv1.HTTPRoute) {
+		httpRoute.Status.Parents = c.mergeRouteParentStatuses(httpRoute, parentStatuses)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to update httproute status: %w", err)
 	}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/metrics"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/references"
+)
+
+// setupEndpointSliceEventHandlers enqueues the owning Service of every changed EndpointSlice on the
+// endpoint queue. Endpoint churn is handled by pushing new ClusterLoadAssignments only, instead of
+// going through the full Gateway sync.
+func (c *controller) setupEndpointSliceEventHandlers(endpointSliceInformer eventSource) error {
+	_, err := endpointSliceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueEndpointSliceService(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSlice, oldOk := oldObj.(*discoveryv1.EndpointSlice)
+			newSlice, newOk := newObj.(*discoveryv1.EndpointSlice)
+			if oldOk && newOk && oldSlice.ResourceVersion == newSlice.ResourceVersion {
+				// Periodic resync, nothing changed.
+				return
+			}
+			c.enqueueEndpointSliceService(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueEndpointSliceService(obj)
+		},
+	})
+	return err
+}
+
+func (c *controller) enqueueEndpointSliceService(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	endpointSlice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		klog.Errorf("Expected EndpointSlice but got %T", obj)
+		return
+	}
+	serviceName, ok := endpointSlice.Labels[discoveryv1.LabelServiceName]
+	if !ok {
+		return
+	}
+	c.endpointqueue.Add(types.NamespacedName{Namespace: endpointSlice.Namespace, Name: serviceName}.String())
+}
+
+func (c *controller) runEndpointWorker(ctx context.Context) {
+	for c.processNextEndpointWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextEndpointWorkItem(ctx context.Context) bool {
+	item, shouldShutdown := c.endpointqueue.Get()
+	if shouldShutdown {
+		return false
+	}
+	defer c.endpointqueue.Done(item)
+
+	startTime := time.Now()
+	c.syncEndpoints(ctx, item)
+	metrics.ReconcileDuration.WithLabelValues("Endpoints").Observe(time.Since(startTime).Seconds())
+	c.endpointqueue.Forget(item)
+	return true
+}
+
+// syncEndpoints pushes fresh ClusterLoadAssignments for a Service to every Gateway that routes to it.
+// Gateways that can't be updated this way are handed over to the full Gateway sync, which also
+// regenerates endpoints, so the endpoint queue itself never needs to retry.
+func (c *controller) syncEndpoints(ctx context.Context, key string) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	service := types.NamespacedName{Namespace: namespace, Name: name}
+
+	gateways := c.tracker.GatewaysFor(references.Ref{Kind: references.KindService, Namespace: namespace, Name: name})
+	for _, gateway := range gateways {
+		logger := klog.FromContext(ctx).WithValues("gateway", klog.KRef(gateway.Namespace, gateway.Name), "service", klog.KRef(namespace, name))
+
+		endpoints, ok := c.translator.TranslateEndpointsToXDS(gateway, service)
+		if !ok {
+			logger.V(4).Info("Gateway has no translated endpoints yet, falling back to full sync")
+			c.gatewayqueue.Add(gateway.String())
+			continue
+		}
+		if len(endpoints) == 0 {
+			continue
+		}
+
+		if err := c.controlplane.PushEndpoints(ctx, c.proxyNodeID(gateway), endpoints); err != nil {
+			logger.Error(err, "Failed to push endpoints, falling back to full sync")
+			metrics.ReconcileErrors.WithLabelValues("Endpoints").Inc()
+			c.gatewayqueue.Add(gateway.String())
+			continue
+		}
+		logger.V(4).Info("Pushed endpoint update", "clusterLoadAssignments", len(endpoints))
+	}
+}
+
+// resyncEndpoints enqueues the Services of the Gateways on the endpoint queue after a full push.
+// The push carries the endpoints read during translation, so it may have overwritten newer ones
+// pushed by the endpoint queue in the meantime, whose EndpointSlice events are already handled.
+func (c *controller) resyncEndpoints(gateways ...types.NamespacedName) {
+	for _, gateway := range gateways {
+		for _, ref := range c.tracker.RefsFor(gateway, references.KindService) {
+			c.endpointqueue.Add(types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}.String())
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayscheme "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/scheme"
+
+	aigatewayscheme "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/k8s/client/clientset/versioned/scheme"
+	envoydeployer "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/deployer/envoy"
+	envoytranslator "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/translator/envoy"
+)
+
+// Reasons of the Events that are not the reason of a status condition.
+const (
+	eventReasonTranslationError    = "TranslationError"
+	eventReasonResourceConflict    = "ResourceConflict"
+	eventReasonProxyConfigRejected = "ProxyConfigRejected"
+)
+
+// newEventRecorder returns a recorder for Events on Gateway API, AI gateway and core objects.
+func newEventRecorder(ctx context.Context, kubeClient kubernetes.Interface, controllerName gatewayv1.GatewayController) record.EventRecorder {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(kubescheme.AddToScheme(scheme))
+	utilruntime.Must(gatewayscheme.AddToScheme(scheme))
+	utilruntime.Must(aigatewayscheme.AddToScheme(scheme))
+
+	broadcaster := record.NewBroadcaster(record.WithContext(ctx))
+	broadcaster.StartStructuredLogging(4)
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme, corev1.EventSource{Component: envoydeployer.ManagedLabelValue(controllerName)})
+}
+
+// recordListenerEvents emits a Warning on the Gateway for each listener that becomes invalid,
+// fails to resolve its references, such as its TLS certificate Secrets, or conflicts with
+// another listener. Problems already reported in the Gateway status are not reported again.
+func (c *controller) recordListenerEvents(gateway *gatewayv1.Gateway, listenerStatuses []gatewayv1.ListenerStatus) {
+	for _, status := range listenerStatuses {
+		var existing []metav1.Condition
+		for _, old := range gateway.Status.Listeners {
+			if old.Name == status.Name {
+				existing = old.Conditions
+				break
+			}
+		}
+		for _, condition := range problemConditions(status.Conditions, existing) {
+			c.recorder.Eventf(gateway, corev1.EventTypeWarning, condition.Reason, "Listener %s: %s", status.Name, condition.Message)
+		}
+	}
+}
+
+// recordRouteEvents emits a Warning on the HTTPRoute for each of our parents that stops
+// accepting it or whose backends stop resolving. Problems already reported in the route status
+// are not reported again.
+func (c *controller) recordRouteEvents(httpRoute *gatewayv1.HTTPRoute, parentStatuses []gatewayv1.RouteParentStatus) {
+	for _, status := range parentStatuses {
+		var existing []metav1.Condition
+		for _, old := range httpRoute.Status.Parents {
+			if old.ControllerName == c.options.ControllerName && apiequality.Semantic.DeepEqual(old.ParentRef, status.ParentRef) {
+				existing = old.Conditions
+				break
+			}
+		}
+		for _, condition := range problemConditions(status.Conditions, existing) {
+			c.recorder.Eventf(httpRoute, corev1.EventTypeWarning, condition.Reason, "Parent %s: %s", parentRefString(httpRoute.Namespace, status.ParentRef), condition.Message)
+		}
+	}
+}
+
+// recordTranslationError emits a Warning on the Gateway that failed to translate, and on the
+// XBackendDestination at fault, if any.
+func (c *controller) recordTranslationError(gateway *gatewayv1.Gateway, err error) {
+	c.recorder.Eventf(gateway, corev1.EventTypeWarning, eventReasonTranslationError, "Failed to translate Gateway: %v", err)
+
+	var backendErr *envoytranslator.BackendError
+	if !errors.As(err, &backendErr) || backendErr.Source == nil || backendErr.Source.Kind != "Backend" {
+		return
+	}
+	backend, getErr := c.aigateway.backendLister.XBackendDestinations(backendErr.Source.Namespace).Get(backendErr.Source.Name)
+	if getErr != nil {
+		return
+	}
+	c.recorder.Eventf(backend, corev1.EventTypeWarning, backendErr.Reason, "Breaks the translation of Gateway %s/%s: %v", gateway.Namespace, gateway.Name, backendErr.Err)
+}
+
+// recordDeployConflict emits a Warning on the Gateways whose proxy resources could not be
+// deployed because unmanaged objects of the same name already exist.
+func (c *controller) recordDeployConflict(gateways []*gatewayv1.Gateway, conflictErr *envoydeployer.ConflictError) {
+	for _, gateway := range gateways {
+		c.recorder.Eventf(gateway, corev1.EventTypeWarning, eventReasonResourceConflict,
+			"Not overwriting resources that are not managed by %s: %s", c.options.ControllerName, strings.Join(conflictErr.Resources, ", "))
+	}
+}
+
+// onProxyNack emits a Warning on the Gateways served by proxies that rejected their
+// configuration. Every replica reports the rejections of the proxies connected to it.
+func (c *controller) onProxyNack(nodeID, cluster, typeURL, message string) {
+	var gateways []*gatewayv1.Gateway
+	if !strings.Contains(cluster, "/") {
+		merged, err := c.mergedGateways(cluster)
+		if err != nil {
+			klog.ErrorS(err, "Failed to list Gateways")
+			return
+		}
+		gateways = merged
+	} else {
+		namespace, name, _ := strings.Cut(cluster, "/")
+		gateway, err := c.gateway.gatewayLister.Gateways(namespace).Get(name)
+		if err != nil {
+			return
+		}
+		gateways = append(gateways, gateway)
+	}
+
+	klog.InfoS("Proxy rejected configuration", "nodeID", nodeID, "cluster", cluster, "typeURL", typeURL, "message", message)
+	for _, gateway := range gateways {
+		c.recorder.Eventf(gateway, corev1.EventTypeWarning, eventReasonProxyConfigRejected,
+			"Proxy %s rejected %s: %s", nodeID, typeURL, message)
+	}
+}
+
+// problemConditions returns the conditions that report a problem, an Accepted or ResolvedRefs
+// condition being False or a Conflicted condition being True, and that are not already reported
+// the same way in existing.
+func problemConditions(conditions, existing []metav1.Condition) []metav1.Condition {
+	var problems []metav1.Condition
+	for _, condition := range conditions {
+		problem := false
+		switch condition.Type {
+		case string(gatewayv1.ListenerConditionAccepted), string(gatewayv1.ListenerConditionResolvedRefs):
+			problem = condition.Status == metav1.ConditionFalse
+		case string(gatewayv1.ListenerConditionConflicted):
+			problem = condition.Status == metav1.ConditionTrue
+		}
+		if !problem {
+			continue
+		}
+		if old := apimeta.FindStatusCondition(existing, condition.Type); old != nil &&
+			old.Status == condition.Status && old.Reason == condition.Reason && old.Message == condition.Message {
+			continue
+		}
+		problems = append(problems, condition)
+	}
+	return problems
+}
+
+func parentRefString(namespace string, ref gatewayv1.ParentReference) string {
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+	s := fmt.Sprintf("%s/%s", namespace, ref.Name)
+	if ref.SectionName != nil {
+		s += "/" + string(*ref.SectionName)
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+	envoydeployer "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/deployer/envoy"
+)
+
+// ensureFinalizer adds the GatewayFinalizer to a Gateway before any infrastructure is deployed
+// for it, and returns the updated Gateway.
+func (c *controller) ensureFinalizer(ctx context.Context, gateway *gatewayv1.Gateway) (*gatewayv1.Gateway, error) {
+	if slices.Contains(gateway.Finalizers, constants.GatewayFinalizer) {
+		return gateway, nil
+	}
+
+	gatewayCopy := gateway.DeepCopy()
+	gatewayCopy.Finalizers = append(gatewayCopy.Finalizers, constants.GatewayFinalizer)
+	updated, err := c.gateway.client.GatewayV1().Gateways(gateway.Namespace).Update(ctx, gatewayCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add finalizer: %w", err)
+	}
+	return updated, nil
+}
+
+// finalizeGateway tears down a Gateway that is being deleted or no longer managed by this
+// controller. Every replica evicts the Gateway's xDS snapshot, and the leader then deletes its
+// infrastructure and releases the finalizer.
+func (c *controller) finalizeGateway(ctx context.Context, gateway *gatewayv1.Gateway) error {
+	logger := klog.FromContext(ctx)
+
+	key := types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}
+	c.forgetGateway(key)
+	c.resyncMergedGateways(key, "")
+
+	if !c.isLeader() || !slices.Contains(gateway.Finalizers, constants.GatewayFinalizer) {
+		return nil
+	}
+	logger.Info("Cleaning up gateway resources", "deleting", gateway.DeletionTimestamp != nil)
+
+	if err := envoydeployer.DeleteGatewayInfra(ctx, c.core.dynamicClient, c.options.ControllerName, gateway); err != nil {
+		return fmt.Errorf("failed to delete gateway infrastructure: %w", err)
+	}
+
+	gatewayCopy := gateway.DeepCopy()
+	gatewayCopy.Finalizers = slices.DeleteFunc(gatewayCopy.Finalizers, func(f string) bool {
+		return f == constants.GatewayFinalizer
+	})
+	if _, err := c.gateway.client.GatewayV1().Gateways(gateway.Namespace).Update(ctx, gatewayCopy, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+	logger.Info("Released gateway finalizer")
+	return nil
+}
+
+// forgetGateway drops everything this replica keeps in memory for a Gateway, including the
+// xDS snapshot of its proxies.
+func (c *controller) forgetGateway(key types.NamespacedName) {
+	c.tracker.Delete(key)
+	c.translator.ForgetGateway(key)
+	c.awaitingAck.Delete(key.String())
+	c.controlplane.ClearSnapshot(envoydeployer.NodeIDForGateway(key))
+}
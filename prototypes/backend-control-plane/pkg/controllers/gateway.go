@@ -1,14 +1,20 @@
 package controllers
 
 import (
+	"slices"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
-	gatewayinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+	envoydeployer "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/deployer/envoy"
 )
 
-func (c *controller) setupGatewayEventHandlers(gatewayInformer gatewayinformers.GatewayInformer) error {
-	_, err := gatewayInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+func (c *controller) setupGatewayEventHandlers(gatewayInformer eventSource) error {
+	_, err := gatewayInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
 			if err == nil {
@@ -20,6 +26,12 @@ func (c *controller) setupGatewayEventHandlers(gatewayInformer gatewayinformers.
 			if err == nil {
 				c.enqueueGateway(newObj, key)
 			}
+			// The Gateways of the previous GatewayClass may share their proxies with this one.
+			oldGateway, oldOk := oldObj.(*gatewayv1.Gateway)
+			newGateway, newOk := newObj.(*gatewayv1.Gateway)
+			if oldOk && newOk && oldGateway.Spec.GatewayClassName != newGateway.Spec.GatewayClassName {
+				c.enqueueMergedGateways(string(oldGateway.Spec.GatewayClassName))
+			}
 		},
 		DeleteFunc: func(obj interface{}) {
 			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
@@ -38,8 +50,9 @@ func (c *controller) enqueueGateway(obj interface{}, key string) {
 		return
 	}
 
-	// Only process Gateways that use our GatewayClass
-	if gateway.Spec.GatewayClassName != "wg-ai-gateway" {
+	// Only process Gateways of our GatewayClasses, and the ones we still hold a finalizer on,
+	// which have left them and need to be torn down.
+	if !c.managesGateway(gateway) && !slices.Contains(gateway.Finalizers, constants.GatewayFinalizer) {
 		klog.V(5).InfoS("Ignoring Gateway with different GatewayClass",
 			"gateway", key,
 			"gatewayClassName", gateway.Spec.GatewayClassName)
@@ -49,3 +62,57 @@ func (c *controller) enqueueGateway(obj interface{}, key string) {
 	klog.V(4).InfoS("Enqueuing Gateway", "gateway", key)
 	c.gatewayqueue.Add(key)
 }
+
+// managesGateway returns whether the Gateway belongs to a GatewayClass of this controller.
+func (c *controller) managesGateway(gateway *gatewayv1.Gateway) bool {
+	gwc, err := c.gateway.gatewayClassLister.Get(string(gateway.Spec.GatewayClassName))
+	if err != nil {
+		return false
+	}
+	return gwc.Spec.ControllerName == c.options.ControllerName
+}
+
+// setupGatewayInfraEventHandlers enqueues the owning Gateway whenever one of the managed objects
+// deployed for it changes, e.g. when its Service is assigned an address, or when someone else
+// edits or deletes it and server-side apply has to restore it.
+func (c *controller) setupGatewayInfraEventHandlers(informer eventSource) error {
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldMeta, oldErr := apimeta.Accessor(oldObj)
+			newMeta, newErr := apimeta.Accessor(newObj)
+			if oldErr == nil && newErr == nil && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+				// Periodic resync, nothing changed.
+				return
+			}
+			c.enqueueOwnerGateway(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueOwnerGateway(obj)
+		},
+	})
+	return err
+}
+
+func (c *controller) enqueueOwnerGateway(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	objMeta, err := apimeta.Accessor(obj)
+	if err != nil {
+		klog.ErrorS(err, "Expected object with metadata", "obj", obj)
+		return
+	}
+	if !envoydeployer.IsManagedBy(objMeta, c.options.ControllerName) {
+		return
+	}
+
+	if owner, ok := envoydeployer.GatewayClassOwner(objMeta); ok {
+		c.enqueueMergedGateways(owner)
+		return
+	}
+	if owner, ok := envoydeployer.GatewayOwner(objMeta); ok {
+		key := types.NamespacedName{Namespace: objMeta.GetNamespace(), Name: owner.Name}
+		klog.V(4).InfoS("Enqueuing Gateway due to infrastructure change", "gateway", key)
+		c.gatewayqueue.Add(key.String())
+	}
+}
@@ -11,13 +11,11 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
-	gatewayinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions/apis/v1"
-
-	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/metrics"
 )
 
-func (c *controller) setupGatewayClassEventHandlers(gatewayClassInformer gatewayinformers.GatewayClassInformer) error {
-	_, err := gatewayClassInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+func (c *controller) setupGatewayClassEventHandlers(gatewayClassInformer eventSource) error {
+	_, err := gatewayClassInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
 			if err == nil {
@@ -45,8 +43,14 @@ func (c *controller) syncGatewayClass(key string) {
 	klog.V(2).Infof("Started syncing gatewayclass %q (%v)", key, time.Since(startTime))
 	defer func() {
 		klog.V(2).Infof("Finished syncing gatewayclass %q (%v)", key, time.Since(startTime))
+		metrics.ReconcileDuration.WithLabelValues("GatewayClass").Observe(time.Since(startTime).Seconds())
 	}()
 
+	// Whether the GatewayClass was added, changed or deleted, its Gateways may have to be
+	// deployed, updated or torn down. The GatewayClass parameters also feed the infrastructure
+	// of all its Gateways.
+	c.enqueueGatewaysForClass(key)
+
 	gwc, err := c.gateway.gatewayClassLister.Get(key)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -55,25 +59,37 @@ func (c *controller) syncGatewayClass(key string) {
 		return
 	}
 
-	// We only care about the GatewayClass that matches our controller name.
-	if gwc.Spec.ControllerName != constants.EnvoyControllerName {
+	// We only care about the GatewayClasses that match our controller name.
+	if gwc.Spec.ControllerName != c.options.ControllerName {
 		klog.V(5).Infof("Ignoring GatewayClass %q with unknown controller name %q", key, gwc.Spec.ControllerName)
 		return
 	}
 
-	newGwc := gwc.DeepCopy()
-	// Set the "Accepted" condition to True and update the observedGeneration.
-	meta.SetStatusCondition(&newGwc.Status.Conditions, metav1.Condition{
+	// Only the leader writes statuses.
+	if !c.isLeader() {
+		return
+	}
+
+	accepted := metav1.Condition{
 		Type:               string(gatewayv1.GatewayClassConditionStatusAccepted),
 		Status:             metav1.ConditionTrue,
 		Reason:             string(gatewayv1.GatewayClassReasonAccepted),
-		Message:            fmt.Sprintf("GatewayClass is accepted by the %s controller.", constants.EnvoyControllerName),
+		Message:            fmt.Sprintf("GatewayClass is accepted by the %s controller.", c.options.ControllerName),
 		ObservedGeneration: gwc.Generation,
-	})
+	}
+	if _, err := c.proxyConfigForGatewayClass(gwc); err != nil {
+		accepted.Status = metav1.ConditionFalse
+		accepted.Reason = string(gatewayv1.GatewayClassReasonInvalidParameters)
+		accepted.Message = err.Error()
+	}
 
-	// Update the GatewayClass status
-	if _, err := c.gateway.client.GatewayV1().GatewayClasses().UpdateStatus(context.Background(), newGwc, metav1.UpdateOptions{}); err != nil {
+	// Set the "Accepted" condition and update the observedGeneration.
+	err = c.writeGatewayClassStatus(context.Background(), gwc, func(gwc *gatewayv1.GatewayClass) {
+		meta.SetStatusCondition(&gwc.Status.Conditions, accepted)
+	})
+	if err != nil {
 		klog.Errorf("failed to update gatewayclass status: %v", err)
+		metrics.ReconcileErrors.WithLabelValues("GatewayClass").Inc()
 	} else {
 		klog.InfoS("GatewayClass status updated", "gatewayclass", key)
 	}
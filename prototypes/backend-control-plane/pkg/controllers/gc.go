@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+	envoydeployer "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/deployer/envoy"
+)
+
+// collectOrphans deletes the managed resources that no Gateway of our GatewayClasses owns
+// anymore, e.g. because the Gateway was deleted while the controller was down or moved to
+// another GatewayClass. In dry-run mode the orphans are only logged.
+func (c *controller) collectOrphans(ctx context.Context) {
+	logger := klog.FromContext(ctx).WithValues("dryRun", c.options.OrphanGCDryRun)
+	logger.V(2).Info("Collecting orphaned gateway infrastructure")
+
+	for _, gvr := range envoydeployer.ManagedResources {
+		client := c.core.dynamicClient.Resource(gvr)
+		// Only the watched namespaces are listed, since owners elsewhere can't be looked up.
+		for _, namespace := range c.namespaces {
+			list, err := client.Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: labels.Set{
+				constants.ManagedGatewayLabel: envoydeployer.ManagedLabelValue(c.options.ControllerName),
+			}.String()})
+			if err != nil {
+				logger.Error(err, "Failed to list managed resources", "resource", gvr.Resource, "namespace", namespace)
+				continue
+			}
+
+			for _, obj := range list.Items {
+				orphan, reason := c.isOrphan(&obj)
+				objLogger := logger.WithValues("resource", gvr.Resource, "object", klog.KObj(&obj))
+				if !orphan {
+					objLogger.V(4).Info("Keeping managed resource", "reason", reason)
+					continue
+				}
+				if c.options.OrphanGCDryRun {
+					objLogger.Info("Would delete orphaned resource", "reason", reason)
+					continue
+				}
+				err := client.Namespace(obj.GetNamespace()).Delete(ctx, obj.GetName(), metav1.DeleteOptions{
+					Preconditions: &metav1.Preconditions{UID: ptr.To(obj.GetUID())},
+				})
+				if err != nil && !apierrors.IsNotFound(err) {
+					objLogger.Error(err, "Failed to delete orphaned resource", "reason", reason)
+					continue
+				}
+				objLogger.Info("Deleted orphaned resource", "reason", reason)
+			}
+		}
+	}
+}
+
+// isOrphan returns whether a managed resource is no longer owned by a Gateway of our
+// GatewayClasses, and the reason for the decision.
+func (c *controller) isOrphan(obj metav1.Object) (bool, string) {
+	if owner, ok := envoydeployer.GatewayClassOwner(obj); ok {
+		gwc, err := c.gateway.gatewayClassLister.Get(owner)
+		if apierrors.IsNotFound(err) {
+			return true, fmt.Sprintf("owning GatewayClass %s does not exist", owner)
+		}
+		if err != nil {
+			return false, err.Error()
+		}
+		if _, merged := c.mergesGateways(gwc); !merged {
+			return true, fmt.Sprintf("owning GatewayClass %s does not merge its Gateways", owner)
+		}
+		return false, fmt.Sprintf("owned by GatewayClass %s", owner)
+	}
+
+	owner, ok := envoydeployer.GatewayOwner(obj)
+	if !ok {
+		return true, "no owning Gateway"
+	}
+	gateway, err := c.gateway.gatewayLister.Gateways(obj.GetNamespace()).Get(owner.Name)
+	if apierrors.IsNotFound(err) {
+		return true, fmt.Sprintf("owning Gateway %s does not exist", owner.Name)
+	}
+	if err != nil {
+		return false, err.Error()
+	}
+	if gateway.UID != owner.UID {
+		return true, fmt.Sprintf("owning Gateway %s was deleted and recreated", owner.Name)
+	}
+	if !c.managesGateway(gateway) {
+		return true, fmt.Sprintf("owning Gateway %s uses GatewayClass %s", owner.Name, gateway.Spec.GatewayClassName)
+	}
+	if _, _, merged := c.mergedGatewayClass(gateway); merged {
+		return true, fmt.Sprintf("owning Gateway %s is served by the shared proxies of GatewayClass %s", owner.Name, gateway.Spec.GatewayClassName)
+	}
+	if c.hasUnmanagedProxies(gateway) {
+		return true, fmt.Sprintf("owning Gateway %s has unmanaged proxies", owner.Name)
+	}
+	return false, fmt.Sprintf("owned by Gateway %s", owner.Name)
+}
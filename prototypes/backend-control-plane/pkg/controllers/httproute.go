@@ -5,11 +5,10 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
-	gatewayinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions/apis/v1"
 )
 
-func (c *controller) setupHTTPRouteEventHandlers(httpRouteInformer gatewayinformers.HTTPRouteInformer) error {
-	_, err := httpRouteInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+func (c *controller) setupHTTPRouteEventHandlers(httpRouteInformer eventSource) error {
+	_, err := httpRouteInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			c.enqueueHTTPRouteParentGateways(obj)
 		},
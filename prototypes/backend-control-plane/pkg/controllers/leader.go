@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// Lead marks this replica as the leader, which allows it to deploy Gateway infrastructure and
+// write statuses, and then re-enqueues every Gateway and GatewayClass so that the work skipped
+// while following is caught up on. It also starts the periodic collection of orphaned
+// infrastructure. Leadership is held until the process exits.
+func (c *controller) Lead(ctx context.Context) {
+	if !cache.WaitForCacheSync(ctx.Done(), c.syncers...) {
+		klog.Error("Failed to wait for caches to sync before leading")
+		return
+	}
+
+	c.leader.Store(true)
+	klog.Info("Became leader, resyncing all Gateways")
+
+	gatewayClasses, err := c.gateway.gatewayClassLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list GatewayClasses: %v", err)
+	}
+	for _, gwc := range gatewayClasses {
+		c.syncGatewayClass(gwc.Name)
+	}
+
+	gateways, err := c.gateway.gatewayLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list Gateways: %v", err)
+		return
+	}
+	for _, gateway := range gateways {
+		key, err := cache.MetaNamespaceKeyFunc(gateway)
+		if err != nil {
+			continue
+		}
+		c.enqueueGateway(gateway, key)
+	}
+
+	if c.options.OrphanGCInterval > 0 {
+		go wait.UntilWithContext(ctx, c.collectOrphans, c.options.OrphanGCInterval)
+	}
+}
+
+// isLeader reports whether this replica may mutate cluster state. Followers still translate
+// Gateways and serve xDS so that proxies can connect to any replica.
+func (c *controller) isLeader() bool {
+	return c.leader.Load()
+}
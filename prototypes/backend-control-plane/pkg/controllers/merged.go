@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/api/v0alpha0"
+	envoydeployer "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/deployer/envoy"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/metrics"
+	envoytranslator "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/translator/envoy"
+)
+
+// proxyFleet identifies the proxies that serve a Gateway: its own, or the ones shared by the
+// Gateways of its GatewayClass.
+type proxyFleet struct {
+	nodeID string
+	// namespace and name are those of the proxy Deployment and Service.
+	namespace string
+	name      string
+	// cluster is the xDS cluster of the proxies, which they report ACKs under.
+	cluster string
+	// unmanaged proxies are provisioned by someone else, and name is only that of their Service.
+	unmanaged bool
+}
+
+func (c *controller) proxyFleetFor(gateway *gatewayv1.Gateway) proxyFleet {
+	if gwc, _, merged := c.mergedGatewayClass(gateway); merged {
+		return mergedProxyFleet(gwc.Name)
+	}
+	key := types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}
+	if c.hasUnmanagedProxies(gateway) {
+		service, _ := envoydeployer.ProxyServiceName(gateway)
+		return proxyFleet{
+			nodeID:    envoydeployer.NodeIDForGateway(key),
+			namespace: gateway.Namespace,
+			name:      service,
+			cluster:   key.String(),
+			unmanaged: true,
+		}
+	}
+	return proxyFleet{
+		nodeID:    envoydeployer.NodeIDForGateway(key),
+		namespace: gateway.Namespace,
+		name:      envoydeployer.ResourceNameForGateway(key),
+		cluster:   key.String(),
+	}
+}
+
+func mergedProxyFleet(gatewayClassName string) proxyFleet {
+	return proxyFleet{
+		nodeID:    envoydeployer.NodeIDForGatewayClass(gatewayClassName),
+		namespace: envoydeployer.MergedGatewaysNamespace,
+		name:      envoydeployer.ResourceNameForGatewayClass(gatewayClassName),
+		cluster:   gatewayClassName,
+	}
+}
+
+// proxyNodeID returns the node ID of the proxies serving the Gateway with the given key.
+func (c *controller) proxyNodeID(key types.NamespacedName) string {
+	gateway, err := c.gateway.gatewayLister.Gateways(key.Namespace).Get(key.Name)
+	if err != nil {
+		return envoydeployer.NodeIDForGateway(key)
+	}
+	return c.proxyFleetFor(gateway).nodeID
+}
+
+// mergedGatewayClass returns the GatewayClass of the Gateway and its proxy configuration if the
+// GatewayClass serves all its Gateways from shared proxies.
+func (c *controller) mergedGatewayClass(gateway *gatewayv1.Gateway) (*gatewayv1.GatewayClass, *v0alpha0.XProxyConfigSpec, bool) {
+	gwc, err := c.gateway.gatewayClassLister.Get(string(gateway.Spec.GatewayClassName))
+	if err != nil {
+		return nil, nil, false
+	}
+	config, merged := c.mergesGateways(gwc)
+	return gwc, config, merged
+}
+
+// mergesGateways returns whether the GatewayClass serves all its Gateways from shared proxies,
+// and its proxy configuration.
+func (c *controller) mergesGateways(gwc *gatewayv1.GatewayClass) (*v0alpha0.XProxyConfigSpec, bool) {
+	if gwc.Spec.ControllerName != c.options.ControllerName {
+		return nil, false
+	}
+	config, err := c.proxyConfigForGatewayClass(gwc)
+	if err != nil || config == nil || config.MergeGateways == nil {
+		return nil, false
+	}
+	return config, *config.MergeGateways
+}
+
+// mergedGateways returns the Gateways of the GatewayClass that are not being deleted, in the
+// order in which they claim ports: oldest first, then alphabetically.
+func (c *controller) mergedGateways(gatewayClassName string) ([]*gatewayv1.Gateway, error) {
+	all, err := c.gateway.gatewayLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var gateways []*gatewayv1.Gateway
+	for _, gateway := range all {
+		if string(gateway.Spec.GatewayClassName) == gatewayClassName && gateway.DeletionTimestamp == nil {
+			gateways = append(gateways, gateway)
+		}
+	}
+	slices.SortFunc(gateways, func(a, b *gatewayv1.Gateway) int {
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+		}
+		if a.Namespace != b.Namespace {
+			return strings.Compare(a.Namespace, b.Namespace)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return gateways, nil
+}
+
+// mergedClassKeyPrefix prefixes the gateway queue keys of GatewayClasses that share their proxies.
+// Namespace names are lowercase, so these keys never collide with the namespace/name key of a
+// Gateway.
+const mergedClassKeyPrefix = "GatewayClass/"
+
+// enqueueMergedGateways enqueues the GatewayClass that shares its proxies between its Gateways.
+// Its Gateways are all synced at once under a single key, so that events of many Gateways of the
+// GatewayClass collapse into one sync.
+func (c *controller) enqueueMergedGateways(gatewayClassName string) {
+	klog.V(4).InfoS("Enqueuing GatewayClass with shared proxies", "gatewayclass", gatewayClassName)
+	c.gatewayqueue.Add(mergedClassKeyPrefix + gatewayClassName)
+}
+
+// syncMergedGatewayClass syncs the Gateways of the GatewayClass with the given name if it still
+// shares its proxies between them. Otherwise its Gateways are synced under their own keys.
+func (c *controller) syncMergedGatewayClass(ctx context.Context, gatewayClassName string) error {
+	gwc, err := c.gateway.gatewayClassLister.Get(gatewayClassName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	config, merged := c.mergesGateways(gwc)
+	if !merged {
+		return nil
+	}
+	return c.syncMergedGateways(ctx, gwc, config)
+}
+
+// syncMergedGateways deploys the proxies shared by the Gateways of a GatewayClass, pushes them
+// the merged translation of all the Gateways, and reports the status of every Gateway. A Gateway
+// that fails to translate is left out of the snapshot, and its error is returned so that the
+// GatewayClass is synced again.
+func (c *controller) syncMergedGateways(ctx context.Context, gwc *gatewayv1.GatewayClass, config *v0alpha0.XProxyConfigSpec) error {
+	logger := klog.FromContext(ctx).WithValues("gatewayclass", gwc.Name)
+
+	gateways, err := c.mergedGateways(gwc.Name)
+	if err != nil {
+		return fmt.Errorf("failed to list gateways: %w", err)
+	}
+	// The shared proxy Service cannot request the addresses of every Gateway, so Gateways that
+	// ask for specific addresses are not merged. They are re-enqueued once their spec changes.
+	gateways = slices.DeleteFunc(gateways, func(gateway *gatewayv1.Gateway) bool {
+		if len(gateway.Spec.Addresses) == 0 {
+			return false
+		}
+		if c.isLeader() {
+			message := fmt.Sprintf("spec.addresses is not supported on Gateways of GatewayClass %s, which share their proxies", gwc.Name)
+			if err := c.updateGatewayNotAccepted(ctx, gateway, gatewayv1.GatewayReasonUnsupportedAddress, message); err != nil {
+				logger.Error(err, "failed to update gateway status", "gateway", klog.KObj(gateway))
+			}
+		}
+		return true
+	})
+	fleet := mergedProxyFleet(gwc.Name)
+
+	if c.isLeader() {
+		deployer := envoydeployer.NewMergedDeployer(
+			c.core.client,
+			c.core.dynamicClient,
+			gwc,
+			gateways,
+			c.envoyProxyImage,
+			c.options.ControllerName,
+			config,
+			c.core.configMapLister,
+			c.core.serviceAccountLister,
+			c.core.serviceLister,
+			c.core.deploymentLister,
+			c.core.hpaLister,
+			c.core.pdbLister,
+		)
+		err := deployer.Deploy(ctx)
+		var conflictErr *envoydeployer.ConflictError
+		if errors.As(err, &conflictErr) {
+			logger.Info("Shared gateway infrastructure conflicts with unmanaged resources", "reason", conflictErr.Error())
+			c.recordDeployConflict(gateways, conflictErr)
+			err = nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to deploy shared gateway infrastructure: %w", err)
+		}
+	}
+
+	var translations []*envoytranslator.GatewayTranslation
+	httpRouteStatuses := make(map[types.NamespacedName][]gatewayv1.RouteParentStatus)
+	var translationErrs []error
+	for _, gateway := range gateways {
+		translationStart := time.Now()
+		resources, listenerStatuses, routeStatuses, err := c.translator.TranslateGatewayAndReferencesToXDS(ctx, gateway)
+		metrics.TranslationDuration.Observe(time.Since(translationStart).Seconds())
+		if err != nil {
+			logger.Error(err, "Failed to translate gateway, leaving it out of the shared proxies", "gateway", klog.KObj(gateway))
+			if c.isLeader() {
+				c.recordTranslationError(gateway, err)
+				if statusErr := c.updateGatewayStatus(ctx, gateway, nil, metav1.ConditionFalse, "TranslationError", err.Error()); statusErr != nil {
+					logger.Error(statusErr, "failed to update gateway status with translation error", "gateway", klog.KObj(gateway))
+				}
+			}
+			translationErrs = append(translationErrs, fmt.Errorf("failed to translate gateway %s/%s to xDS resources: %w", gateway.Namespace, gateway.Name, err))
+			continue
+		}
+		translations = append(translations, &envoytranslator.GatewayTranslation{
+			Gateway:          gateway,
+			Resources:        resources,
+			ListenerStatuses: listenerStatuses,
+		})
+		// A route can be attached to several of the Gateways.
+		for key, statuses := range routeStatuses {
+			httpRouteStatuses[key] = append(httpRouteStatuses[key], statuses...)
+		}
+	}
+
+	if err := c.controlplane.PushXDS(ctx, fleet.nodeID, envoytranslator.MergeTranslations(translations)); err != nil {
+		return fmt.Errorf("failed to update xDS server: %w", err)
+	}
+	for _, translation := range translations {
+		key := types.NamespacedName{Namespace: translation.Gateway.Namespace, Name: translation.Gateway.Name}
+		c.mergedClasses.Store(key, gwc.Name)
+		c.resyncEndpoints(key)
+	}
+	logger.Info("Updated xDS server with merged gateway resources", "nodeID", fleet.nodeID, "gateways", len(translations))
+
+	// Only the leader writes statuses.
+	if !c.isLeader() {
+		return errors.Join(translationErrs...)
+	}
+
+	pending, err := c.proxyPendingReason(fleet)
+	if err != nil {
+		return fmt.Errorf("failed to check gateway proxies: %w", err)
+	}
+	programmed, reason, message := metav1.ConditionTrue, "Programmed", "Gateway is programmed and ready"
+	if pending != "" {
+		c.awaitingAck.Store(fleet.cluster, struct{}{})
+		programmed, reason, message = metav1.ConditionFalse, string(gatewayv1.GatewayReasonPending), pending
+	} else {
+		c.awaitingAck.Delete(fleet.cluster)
+	}
+
+	for _, translation := range translations {
+		if err := c.updateGatewayStatus(ctx, translation.Gateway, translation.ListenerStatuses, programmed, reason, message); err != nil {
+			logger.Error(err, "failed to update gateway status", "gateway", klog.KObj(translation.Gateway))
+		}
+	}
+	for httpRouteKey, parentStatuses := range httpRouteStatuses {
+		if err := c.updateHTTPRouteStatus(ctx, httpRouteKey, parentStatuses); err != nil {
+			logger.Error(err, "failed to update httproute status", "httproute", httpRouteKey)
+		}
+	}
+
+	if len(translationErrs) > 0 {
+		return errors.Join(translationErrs...)
+	}
+	if pending != "" {
+		return errGatewayPending
+	}
+	return nil
+}
+
+// resyncMergedGateways re-syncs the GatewayClass whose shared proxies last served a Gateway, if
+// that is not the GatewayClass currently serving it, so that its listeners are removed from them.
+// current is the name of the merged GatewayClass of the Gateway, if any.
+//
+// The GatewayClass is the one recorded by the last merged sync of the Gateway rather than the one
+// in its spec, which no longer names it once the Gateway moved to another GatewayClass.
+func (c *controller) resyncMergedGateways(key types.NamespacedName, current string) {
+	previous, ok := c.mergedClasses.Load(key)
+	if !ok || previous.(string) == current {
+		return
+	}
+	c.mergedClasses.Delete(key)
+	c.enqueueMergedGateways(previous.(string))
+}
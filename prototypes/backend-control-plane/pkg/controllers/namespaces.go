@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"errors"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	gatewayinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
+
+	aigatewayinformers "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/k8s/client/informers/externalversions"
+)
+
+// InformerFactories are the informer factories the controller watches resources through.
+type InformerFactories struct {
+	// Namespace is the namespace the factories are scoped to, or empty for all namespaces.
+	Namespace string
+	Kube      kubeinformers.SharedInformerFactory
+	Gateway   gatewayinformers.SharedInformerFactory
+	AIGateway aigatewayinformers.SharedInformerFactory
+}
+
+// eventSource is the part of a shared informer that event handlers are registered on.
+type eventSource interface {
+	AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error)
+}
+
+// namespacedInformer watches a namespaced resource through one informer per watched namespace.
+type namespacedInformer struct {
+	informers   []cache.SharedIndexInformer
+	byNamespace map[string]cache.SharedIndexInformer
+}
+
+func newNamespacedInformer(factories []InformerFactories, informerFor func(InformerFactories) cache.SharedIndexInformer) *namespacedInformer {
+	ni := &namespacedInformer{byNamespace: map[string]cache.SharedIndexInformer{}}
+	for _, factories := range factories {
+		informer := informerFor(factories)
+		ni.informers = append(ni.informers, informer)
+		ni.byNamespace[factories.Namespace] = informer
+	}
+	return ni
+}
+
+func (ni *namespacedInformer) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	var registration cache.ResourceEventHandlerRegistration
+	for _, informer := range ni.informers {
+		var err error
+		if registration, err = informer.AddEventHandler(handler); err != nil {
+			return nil, err
+		}
+	}
+	return registration, nil
+}
+
+func (ni *namespacedInformer) HasSynced() bool {
+	for _, informer := range ni.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// GetIndexer returns an indexer over the caches of all the watched namespaces, which listers
+// can be built on.
+func (ni *namespacedInformer) GetIndexer() cache.Indexer {
+	if informer, ok := ni.byNamespace[metav1.NamespaceAll]; ok {
+		return informer.GetIndexer()
+	}
+	return &namespacedIndexer{informers: ni}
+}
+
+// errReadOnlyIndexer is returned when something tries to modify a namespacedIndexer.
+var errReadOnlyIndexer = errors.New("namespaced indexer is read-only")
+
+// namespacedIndexer is a read-only view over the caches of several namespaces. Objects in other
+// namespaces are never found.
+type namespacedIndexer struct {
+	informers *namespacedInformer
+}
+
+var _ cache.Indexer = &namespacedIndexer{}
+
+func (i *namespacedIndexer) indexer(namespace string) (cache.Indexer, bool) {
+	informer, ok := i.informers.byNamespace[namespace]
+	if !ok {
+		return nil, false
+	}
+	return informer.GetIndexer(), true
+}
+
+func (i *namespacedIndexer) List() []interface{} {
+	var items []interface{}
+	for _, informer := range i.informers.informers {
+		items = append(items, informer.GetIndexer().List()...)
+	}
+	return items
+}
+
+func (i *namespacedIndexer) ListKeys() []string {
+	var keys []string
+	for _, informer := range i.informers.informers {
+		keys = append(keys, informer.GetIndexer().ListKeys()...)
+	}
+	return keys
+}
+
+func (i *namespacedIndexer) Get(obj interface{}) (interface{}, bool, error) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return nil, false, cache.KeyError{Obj: obj, Err: err}
+	}
+	return i.GetByKey(key)
+}
+
+func (i *namespacedIndexer) GetByKey(key string) (interface{}, bool, error) {
+	namespace, _, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	indexer, ok := i.indexer(namespace)
+	if !ok {
+		return nil, false, nil
+	}
+	return indexer.GetByKey(key)
+}
+
+func (i *namespacedIndexer) Index(indexName string, obj interface{}) ([]interface{}, error) {
+	if indexName == cache.NamespaceIndex {
+		objMeta, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		indexer, ok := i.indexer(objMeta.GetNamespace())
+		if !ok {
+			return nil, nil
+		}
+		return indexer.Index(indexName, obj)
+	}
+
+	var items []interface{}
+	for _, informer := range i.informers.informers {
+		found, err := informer.GetIndexer().Index(indexName, obj)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, found...)
+	}
+	return items, nil
+}
+
+func (i *namespacedIndexer) IndexKeys(indexName, indexedValue string) ([]string, error) {
+	var keys []string
+	for _, informer := range i.informers.informers {
+		found, err := informer.GetIndexer().IndexKeys(indexName, indexedValue)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, found...)
+	}
+	return keys, nil
+}
+
+func (i *namespacedIndexer) ListIndexFuncValues(indexName string) []string {
+	values := sets.New[string]()
+	for _, informer := range i.informers.informers {
+		values.Insert(informer.GetIndexer().ListIndexFuncValues(indexName)...)
+	}
+	return sets.List(values)
+}
+
+func (i *namespacedIndexer) ByIndex(indexName, indexedValue string) ([]interface{}, error) {
+	var items []interface{}
+	for _, informer := range i.informers.informers {
+		found, err := informer.GetIndexer().ByIndex(indexName, indexedValue)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, found...)
+	}
+	return items, nil
+}
+
+func (i *namespacedIndexer) GetIndexers() cache.Indexers {
+	return i.informers.informers[0].GetIndexer().GetIndexers()
+}
+
+func (i *namespacedIndexer) AddIndexers(cache.Indexers) error { return errReadOnlyIndexer }
+func (i *namespacedIndexer) Add(interface{}) error            { return errReadOnlyIndexer }
+func (i *namespacedIndexer) Update(interface{}) error         { return errReadOnlyIndexer }
+func (i *namespacedIndexer) Delete(interface{}) error         { return errReadOnlyIndexer }
+func (i *namespacedIndexer) Replace([]interface{}, string) error {
+	return errReadOnlyIndexer
+}
+func (i *namespacedIndexer) Resync() error { return errReadOnlyIndexer }
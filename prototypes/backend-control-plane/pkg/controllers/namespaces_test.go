@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNamespacedIndexer(t *testing.T) {
+	service := func(namespace, name string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+
+	var factories []InformerFactories
+	for _, namespace := range []string{"default", "models"} {
+		factories = append(factories, InformerFactories{Namespace: namespace})
+	}
+	informers := newNamespacedInformer(factories, func(InformerFactories) cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(&cache.ListWatch{}, &corev1.Service{}, 0,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	})
+	for _, svc := range []*corev1.Service{service("default", "a"), service("default", "b"), service("models", "llm")} {
+		if err := informers.byNamespace[svc.Namespace].GetIndexer().Add(svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indexer := informers.GetIndexer()
+
+	t.Run("GetByKey", func(t *testing.T) {
+		for key, wantExists := range map[string]bool{
+			"default/a":   true,
+			"models/llm":  true,
+			"models/a":    false,
+			"unwatched/a": false,
+		} {
+			obj, exists, err := indexer.GetByKey(key)
+			if err != nil {
+				t.Fatalf("GetByKey(%q) error = %v", key, err)
+			}
+			if exists != wantExists {
+				t.Errorf("GetByKey(%q) exists = %v, want %v", key, exists, wantExists)
+			}
+			if exists {
+				if got, _ := cache.MetaNamespaceKeyFunc(obj); got != key {
+					t.Errorf("GetByKey(%q) returned %s", key, got)
+				}
+			}
+		}
+	})
+
+	t.Run("Index by namespace", func(t *testing.T) {
+		for namespace, want := range map[string][]string{
+			"default":   {"default/a", "default/b"},
+			"models":    {"models/llm"},
+			"unwatched": nil,
+		} {
+			items, err := indexer.Index(cache.NamespaceIndex, &metav1.ObjectMeta{Namespace: namespace})
+			if err != nil {
+				t.Fatalf("Index(%q) error = %v", namespace, err)
+			}
+			var got []string
+			for _, item := range items {
+				key, _ := cache.MetaNamespaceKeyFunc(item)
+				got = append(got, key)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, want) {
+				t.Errorf("Index(%q) = %v, want %v", namespace, got, want)
+			}
+		}
+	})
+
+	t.Run("List spans the watched namespaces", func(t *testing.T) {
+		keys := indexer.ListKeys()
+		slices.Sort(keys)
+		if want := []string{"default/a", "default/b", "models/llm"}; !slices.Equal(keys, want) {
+			t.Errorf("ListKeys() = %v, want %v", keys, want)
+		}
+		if got := len(indexer.List()); got != 3 {
+			t.Errorf("List() returned %d objects, want 3", got)
+		}
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		if err := indexer.Add(service("default", "c")); err != errReadOnlyIndexer {
+			t.Errorf("Add() error = %v, want %v", err, errReadOnlyIndexer)
+		}
+		if _, exists, _ := indexer.GetByKey("default/c"); exists {
+			t.Errorf("Add() modified the indexer")
+		}
+	})
+
+	t.Run("all namespaces use the informer's own indexer", func(t *testing.T) {
+		all := newNamespacedInformer([]InformerFactories{{Namespace: metav1.NamespaceAll}}, func(InformerFactories) cache.SharedIndexInformer {
+			return cache.NewSharedIndexInformer(&cache.ListWatch{}, &corev1.Service{}, 0, cache.Indexers{})
+		})
+		if _, ok := all.GetIndexer().(*namespacedIndexer); ok {
+			t.Errorf("GetIndexer() for all namespaces returned a namespacedIndexer")
+		}
+	})
+}
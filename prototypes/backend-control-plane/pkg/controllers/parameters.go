@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/api/v0alpha0"
+)
+
+// proxyConfigKind is the kind that GatewayClass and Gateway parametersRefs must point to.
+const proxyConfigKind = "XProxyConfig"
+
+// invalidParametersError reports a parametersRef that cannot be resolved.
+type invalidParametersError struct {
+	message string
+}
+
+func (e *invalidParametersError) Error() string {
+	return e.message
+}
+
+// proxyConfigForGatewayClass returns the proxy configuration referenced by the GatewayClass,
+// or nil if it has no parametersRef.
+func (c *controller) proxyConfigForGatewayClass(gwc *gatewayv1.GatewayClass) (*v0alpha0.XProxyConfigSpec, error) {
+	ref := gwc.Spec.ParametersRef
+	if ref == nil {
+		return nil, nil
+	}
+	if string(ref.Group) != v0alpha0.GroupName || string(ref.Kind) != proxyConfigKind {
+		return nil, &invalidParametersError{fmt.Sprintf("unsupported parametersRef %s/%s, must be %s/%s", ref.Group, ref.Kind, v0alpha0.GroupName, proxyConfigKind)}
+	}
+	if ref.Namespace == nil {
+		return nil, &invalidParametersError{fmt.Sprintf("parametersRef to %s %q must set a namespace", proxyConfigKind, ref.Name)}
+	}
+	return c.getProxyConfig(string(*ref.Namespace), ref.Name)
+}
+
+// proxyConfigForGateway returns the proxy configuration of the Gateway: the configuration of
+// its GatewayClass, overridden field by field by its own infrastructure parametersRef.
+func (c *controller) proxyConfigForGateway(gateway *gatewayv1.Gateway) (*v0alpha0.XProxyConfigSpec, error) {
+	var config *v0alpha0.XProxyConfigSpec
+	gwc, err := c.gateway.gatewayClassLister.Get(string(gateway.Spec.GatewayClassName))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		if config, err = c.proxyConfigForGatewayClass(gwc); err != nil {
+			var invalidErr *invalidParametersError
+			if errors.As(err, &invalidErr) {
+				return nil, &invalidParametersError{fmt.Sprintf("GatewayClass %q has invalid parameters: %v", gwc.Name, err)}
+			}
+			return nil, err
+		}
+	}
+
+	if gateway.Spec.Infrastructure == nil || gateway.Spec.Infrastructure.ParametersRef == nil {
+		return config, nil
+	}
+	ref := gateway.Spec.Infrastructure.ParametersRef
+	if string(ref.Group) != v0alpha0.GroupName || string(ref.Kind) != proxyConfigKind {
+		return nil, &invalidParametersError{fmt.Sprintf("unsupported infrastructure parametersRef %s/%s, must be %s/%s", ref.Group, ref.Kind, v0alpha0.GroupName, proxyConfigKind)}
+	}
+	override, err := c.getProxyConfig(gateway.Namespace, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	return mergeProxyConfig(config, override), nil
+}
+
+func (c *controller) getProxyConfig(namespace, name string) (*v0alpha0.XProxyConfigSpec, error) {
+	proxyConfig, err := c.aigateway.proxyConfigLister.XProxyConfigs(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, &invalidParametersError{fmt.Sprintf("%s %s/%s not found", proxyConfigKind, namespace, name)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &proxyConfig.Spec, nil
+}
+
+// mergeProxyConfig returns base with every field set in override replaced.
+func mergeProxyConfig(base, override *v0alpha0.XProxyConfigSpec) *v0alpha0.XProxyConfigSpec {
+	if base == nil {
+		return override
+	}
+	merged := base.DeepCopy()
+	if override.Image != nil {
+		merged.Image = override.Image
+	}
+	if override.Replicas != nil {
+		merged.Replicas = override.Replicas
+	}
+	if override.Resources != nil {
+		merged.Resources = override.Resources
+	}
+	if override.LogLevel != nil {
+		merged.LogLevel = override.LogLevel
+	}
+	if override.ServiceType != nil {
+		merged.ServiceType = override.ServiceType
+	}
+	if override.Autoscaling != nil {
+		merged.Autoscaling = override.Autoscaling
+	}
+	if override.PodDisruptionBudget != nil {
+		merged.PodDisruptionBudget = override.PodDisruptionBudget
+	}
+	return merged
+}
+
+// setupProxyConfigEventHandlers re-syncs the GatewayClasses and Gateways that reference an
+// XProxyConfig whenever it changes.
+func (c *controller) setupProxyConfigEventHandlers(informer eventSource) error {
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueProxyConfigDependents(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldMeta, oldErr := apimeta.Accessor(oldObj)
+			newMeta, newErr := apimeta.Accessor(newObj)
+			if oldErr == nil && newErr == nil && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+				// Periodic resync, nothing changed.
+				return
+			}
+			c.enqueueProxyConfigDependents(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueProxyConfigDependents(obj)
+		},
+	})
+	return err
+}
+
+func (c *controller) enqueueProxyConfigDependents(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	objMeta, err := apimeta.Accessor(obj)
+	if err != nil {
+		klog.ErrorS(err, "Expected object with metadata", "obj", obj)
+		return
+	}
+
+	gatewayClasses, err := c.gateway.gatewayClassLister.List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list GatewayClasses")
+		return
+	}
+	for _, gwc := range gatewayClasses {
+		ref := gwc.Spec.ParametersRef
+		if ref == nil || string(ref.Kind) != proxyConfigKind || ref.Name != objMeta.GetName() ||
+			ref.Namespace == nil || string(*ref.Namespace) != objMeta.GetNamespace() {
+			continue
+		}
+		// Syncing the GatewayClass also enqueues its Gateways.
+		c.syncGatewayClass(gwc.Name)
+	}
+
+	gateways, err := c.gateway.gatewayLister.Gateways(objMeta.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list Gateways", "namespace", objMeta.GetNamespace())
+		return
+	}
+	for _, gateway := range gateways {
+		if gateway.Spec.Infrastructure == nil || gateway.Spec.Infrastructure.ParametersRef == nil {
+			continue
+		}
+		ref := gateway.Spec.Infrastructure.ParametersRef
+		if string(ref.Kind) != proxyConfigKind || ref.Name != objMeta.GetName() {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(gateway)
+		if err == nil {
+			c.enqueueGateway(gateway, key)
+		}
+	}
+}
+
+// enqueueGatewaysForClass enqueues every Gateway of the GatewayClass.
+func (c *controller) enqueueGatewaysForClass(gatewayClassName string) {
+	gateways, err := c.gateway.gatewayLister.List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list Gateways")
+		return
+	}
+	for _, gateway := range gateways {
+		if string(gateway.Spec.GatewayClassName) != gatewayClassName {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(gateway)
+		if err == nil {
+			c.enqueueGateway(gateway, key)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+)
+
+// errGatewayPending is returned by syncHandler when the Gateway's proxies are not ready yet, so
+// that the Gateway is checked again with backoff. Deployment events and proxy ACKs re-enqueue it
+// right away.
+var errGatewayPending = errors.New("gateway proxies are not ready")
+
+// proxyPendingReason returns why the proxies are not ready to serve their current configuration,
+// or an empty string if they are.
+func (c *controller) proxyPendingReason(fleet proxyFleet) (string, error) {
+	if fleet.unmanaged {
+		// Unmanaged proxies have no Deployment we know of, so their ACKs are the only sign that
+		// they exist and use the right node ID. The messages name the node ID and cluster the
+		// proxies must connect with, since no one else reports them.
+		if fleet.name == "" {
+			return fmt.Sprintf("Waiting for the %s annotation to name the Service of the unmanaged proxies with %s", constants.ProxyServiceAnnotation, unmanagedProxyIdentity(fleet)), nil
+		}
+		acked, connected := c.controlplane.SnapshotAcked(fleet.nodeID)
+		if !connected {
+			return fmt.Sprintf("Waiting for unmanaged proxies with %s to connect and acknowledge the current configuration; "+
+				"only proxies connected to the leader replica of the controller are seen", unmanagedProxyIdentity(fleet)), nil
+		}
+		if !acked {
+			return fmt.Sprintf("Waiting for unmanaged proxies with %s to acknowledge the current configuration", unmanagedProxyIdentity(fleet)), nil
+		}
+		return "", nil
+	}
+
+	deployment, err := c.core.deploymentLister.Deployments(fleet.namespace).Get(fleet.name)
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("Waiting for proxy Deployment %s/%s to be created", fleet.namespace, fleet.name), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !deploymentAvailable(deployment) {
+		return fmt.Sprintf("Waiting for proxy Deployment %s/%s to become available", fleet.namespace, fleet.name), nil
+	}
+
+	// Proxies connected to another replica of the controller can't be checked from here, in which
+	// case an available Deployment is the best signal we have.
+	if acked, connected := c.controlplane.SnapshotAcked(fleet.nodeID); connected && !acked {
+		return "Waiting for proxies to acknowledge the current configuration", nil
+	}
+	return "", nil
+}
+
+// unmanagedProxyIdentity describes the xDS node ID and cluster that unmanaged proxies must
+// connect with to be served their Gateway's configuration.
+func unmanagedProxyIdentity(fleet proxyFleet) string {
+	return fmt.Sprintf("node ID %s and cluster %s", fleet.nodeID, fleet.cluster)
+}
+
+func deploymentAvailable(deployment *appsv1.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false
+	}
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentAvailable {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// onProxyAck re-enqueues a Gateway that is waiting for its proxies to ACK their configuration.
+// Proxies shared by the Gateways of a GatewayClass use the GatewayClass name as their cluster.
+func (c *controller) onProxyAck(nodeID, cluster string) {
+	if _, waiting := c.awaitingAck.LoadAndDelete(cluster); !waiting {
+		return
+	}
+	if !strings.Contains(cluster, "/") {
+		c.enqueueMergedGateways(cluster)
+		return
+	}
+	klog.V(4).InfoS("Enqueuing Gateway after proxy ACK", "gateway", cluster, "nodeID", nodeID)
+	c.gatewayqueue.Add(cluster)
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/references"
+)
+
+// refFunc maps an object to the reference Gateways record when they read it.
+// It returns false if the object cannot be a dependency of any Gateway.
+type refFunc func(obj metav1.Object) (references.Ref, bool)
+
+// setupReferenceEventHandlers enqueues the Gateways that depend on an object of the
+// informer's kind whenever that object changes.
+func (c *controller) setupReferenceEventHandlers(informer eventSource, toRef refFunc) error {
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueDependentGateways(obj, toRef)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldMeta, oldErr := apimeta.Accessor(oldObj)
+			newMeta, newErr := apimeta.Accessor(newObj)
+			if oldErr == nil && newErr == nil && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+				// Periodic resync, nothing changed.
+				return
+			}
+			c.enqueueDependentGateways(newObj, toRef)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueDependentGateways(obj, toRef)
+		},
+	})
+	return err
+}
+
+func (c *controller) enqueueDependentGateways(obj interface{}, toRef refFunc) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	objMeta, err := apimeta.Accessor(obj)
+	if err != nil {
+		klog.ErrorS(err, "Expected object with metadata", "obj", obj)
+		return
+	}
+
+	ref, ok := toRef(objMeta)
+	if !ok {
+		return
+	}
+
+	for _, gateway := range c.tracker.GatewaysFor(ref) {
+		klog.V(4).InfoS("Enqueuing Gateway due to dependency change",
+			"gateway", gateway,
+			"kind", ref.Kind,
+			"object", klog.KRef(ref.Namespace, ref.Name))
+		c.gatewayqueue.Add(gateway.String())
+	}
+}
+
+func namespacedRef(kind references.Kind) refFunc {
+	return func(obj metav1.Object) (references.Ref, bool) {
+		return references.Ref{Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}, true
+	}
+}
+
+func namespaceRef(obj metav1.Object) (references.Ref, bool) {
+	return references.Ref{Kind: references.KindNamespace, Name: obj.GetName()}, true
+}
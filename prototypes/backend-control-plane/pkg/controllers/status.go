@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// writeGatewayStatus applies mutate to a copy of the Gateway and writes its status if it changed.
+// On conflicts, mutate is applied again to the latest version of the Gateway, so that it only
+// changes the fields this controller owns.
+func (c *controller) writeGatewayStatus(ctx context.Context, gateway *gatewayv1.Gateway, mutate func(*gatewayv1.Gateway)) error {
+	client := c.gateway.client.GatewayV1().Gateways(gateway.Namespace)
+	current := gateway
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		updated := current.DeepCopy()
+		mutate(updated)
+		if apiequality.Semantic.DeepEqual(current.Status, updated.Status) {
+			return nil
+		}
+		_, err := client.UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+		if apierrors.IsConflict(err) {
+			latest, getErr := client.Get(ctx, gateway.Name, metav1.GetOptions{})
+			if getErr != nil {
+				return getErr
+			}
+			current = latest
+		}
+		return err
+	})
+}
+
+// writeHTTPRouteStatus is writeGatewayStatus for HTTPRoutes.
+func (c *controller) writeHTTPRouteStatus(ctx context.Context, httpRoute *gatewayv1.HTTPRoute, mutate func(*gatewayv1.HTTPRoute)) error {
+	client := c.gateway.client.GatewayV1().HTTPRoutes(httpRoute.Namespace)
+	current := httpRoute
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		updated := current.DeepCopy()
+		mutate(updated)
+		if apiequality.Semantic.DeepEqual(current.Status, updated.Status) {
+			return nil
+		}
+		_, err := client.UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+		if apierrors.IsConflict(err) {
+			latest, getErr := client.Get(ctx, httpRoute.Name, metav1.GetOptions{})
+			if getErr != nil {
+				return getErr
+			}
+			current = latest
+		}
+		return err
+	})
+}
+
+// writeGatewayClassStatus is writeGatewayStatus for GatewayClasses.
+func (c *controller) writeGatewayClassStatus(ctx context.Context, gwc *gatewayv1.GatewayClass, mutate func(*gatewayv1.GatewayClass)) error {
+	client := c.gateway.client.GatewayV1().GatewayClasses()
+	current := gwc
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		updated := current.DeepCopy()
+		mutate(updated)
+		if apiequality.Semantic.DeepEqual(current.Status, updated.Status) {
+			return nil
+		}
+		_, err := client.UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+		if apierrors.IsConflict(err) {
+			latest, getErr := client.Get(ctx, gwc.Name, metav1.GetOptions{})
+			if getErr != nil {
+				return getErr
+			}
+			current = latest
+		}
+		return err
+	})
+}
+
+// mergeConditions returns a copy of the desired conditions that keeps the LastTransitionTime of
+// the existing conditions whose status did not change.
+func mergeConditions(existing, desired []metav1.Condition) []metav1.Condition {
+	merged := make([]metav1.Condition, 0, len(desired))
+	for _, condition := range desired {
+		if old := apimeta.FindStatusCondition(existing, condition.Type); old != nil && old.Status == condition.Status {
+			condition.LastTransitionTime = old.LastTransitionTime
+		}
+		merged = append(merged, condition)
+	}
+	return merged
+}
+
+// mergeListenerStatuses returns the desired listener statuses, keeping the transition times of
+// the existing statuses of the same listeners.
+func mergeListenerStatuses(existing, desired []gatewayv1.ListenerStatus) []gatewayv1.ListenerStatus {
+	merged := make([]gatewayv1.ListenerStatus, 0, len(desired))
+	for _, status := range desired {
+		status := *status.DeepCopy()
+		for _, old := range existing {
+			if old.Name == status.Name {
+				status.Conditions = mergeConditions(old.Conditions, status.Conditions)
+				break
+			}
+		}
+		merged = append(merged, status)
+	}
+	return merged
+}
+
+// mergeRouteParentStatuses returns the parent statuses of the route with ours written in. The
+// entries of other controllers are kept as they are. Our entries are replaced for the parentRefs
+// in ours, and dropped for the parentRefs the route no longer has.
+func (c *controller) mergeRouteParentStatuses(httpRoute *gatewayv1.HTTPRoute, ours []gatewayv1.RouteParentStatus) []gatewayv1.RouteParentStatus {
+	merged := make([]gatewayv1.RouteParentStatus, 0, len(httpRoute.Status.Parents)+len(ours))
+	written := make([]bool, len(ours))
+
+	for _, existing := range httpRoute.Status.Parents {
+		if existing.ControllerName != c.options.ControllerName {
+			merged = append(merged, existing)
+			continue
+		}
+		if i := indexOfParentRef(ours, existing.ParentRef); i >= 0 {
+			if !written[i] {
+				status := *ours[i].DeepCopy()
+				status.Conditions = mergeConditions(existing.Conditions, status.Conditions)
+				merged = append(merged, status)
+				written[i] = true
+			}
+			continue
+		}
+		if hasParentRef(httpRoute.Spec.ParentRefs, existing.ParentRef) {
+			merged = append(merged, existing)
+		}
+	}
+	for i, status := range ours {
+		if !written[i] {
+			merged = append(merged, *status.DeepCopy())
+		}
+	}
+	return merged
+}
+
+func indexOfParentRef(statuses []gatewayv1.RouteParentStatus, ref gatewayv1.ParentReference) int {
+	for i, status := range statuses {
+		if apiequality.Semantic.DeepEqual(status.ParentRef, ref) {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasParentRef(refs []gatewayv1.ParentReference, ref gatewayv1.ParentReference) bool {
+	for _, r := range refs {
+		if apiequality.Semantic.DeepEqual(r, ref) {
+			return true
+		}
+	}
+	return false
+}
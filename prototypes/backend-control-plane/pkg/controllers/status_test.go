@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestMergeRouteParentStatuses(t *testing.T) {
+	const (
+		ourController   = gatewayv1.GatewayController("sigs.k8s.io/wg-ai-gateway-envoy-controller")
+		otherController = gatewayv1.GatewayController("example.com/other-controller")
+	)
+	gatewayA := gatewayv1.ParentReference{Name: "a"}
+	gatewayB := gatewayv1.ParentReference{Name: "b"}
+	status := func(ref gatewayv1.ParentReference, controller gatewayv1.GatewayController, accepted metav1.ConditionStatus) gatewayv1.RouteParentStatus {
+		return gatewayv1.RouteParentStatus{
+			ParentRef:      ref,
+			ControllerName: controller,
+			Conditions: []metav1.Condition{{
+				Type:   string(gatewayv1.RouteConditionAccepted),
+				Status: accepted,
+				Reason: string(gatewayv1.RouteReasonAccepted),
+			}},
+		}
+	}
+
+	transitioned := func(s gatewayv1.RouteParentStatus) gatewayv1.RouteParentStatus {
+		s.Conditions[0].LastTransitionTime = metav1.Unix(1, 0)
+		return s
+	}
+
+	tests := []struct {
+		name       string
+		parentRefs []gatewayv1.ParentReference
+		existing   []gatewayv1.RouteParentStatus
+		ours       []gatewayv1.RouteParentStatus
+		want       []gatewayv1.RouteParentStatus
+	}{
+		{
+			name:       "new entry is appended",
+			parentRefs: []gatewayv1.ParentReference{gatewayA},
+			ours:       []gatewayv1.RouteParentStatus{status(gatewayA, ourController, metav1.ConditionTrue)},
+			want:       []gatewayv1.RouteParentStatus{status(gatewayA, ourController, metav1.ConditionTrue)},
+		},
+		{
+			name:       "entries of other controllers are kept",
+			parentRefs: []gatewayv1.ParentReference{gatewayA},
+			existing:   []gatewayv1.RouteParentStatus{status(gatewayA, otherController, metav1.ConditionFalse)},
+			ours:       []gatewayv1.RouteParentStatus{status(gatewayA, ourController, metav1.ConditionTrue)},
+			want: []gatewayv1.RouteParentStatus{
+				status(gatewayA, otherController, metav1.ConditionFalse),
+				status(gatewayA, ourController, metav1.ConditionTrue),
+			},
+		},
+		{
+			name:       "our entry is replaced in place",
+			parentRefs: []gatewayv1.ParentReference{gatewayA, gatewayB},
+			existing: []gatewayv1.RouteParentStatus{
+				status(gatewayA, ourController, metav1.ConditionFalse),
+				status(gatewayB, otherController, metav1.ConditionTrue),
+			},
+			ours: []gatewayv1.RouteParentStatus{status(gatewayA, ourController, metav1.ConditionTrue)},
+			want: []gatewayv1.RouteParentStatus{
+				status(gatewayA, ourController, metav1.ConditionTrue),
+				status(gatewayB, otherController, metav1.ConditionTrue),
+			},
+		},
+		{
+			name:       "our entry for a parentRef we did not sync is kept",
+			parentRefs: []gatewayv1.ParentReference{gatewayA, gatewayB},
+			existing:   []gatewayv1.RouteParentStatus{status(gatewayB, ourController, metav1.ConditionTrue)},
+			ours:       []gatewayv1.RouteParentStatus{status(gatewayA, ourController, metav1.ConditionTrue)},
+			want: []gatewayv1.RouteParentStatus{
+				status(gatewayB, ourController, metav1.ConditionTrue),
+				status(gatewayA, ourController, metav1.ConditionTrue),
+			},
+		},
+		{
+			name:       "our entry for a dropped parentRef is removed",
+			parentRefs: []gatewayv1.ParentReference{gatewayA},
+			existing: []gatewayv1.RouteParentStatus{
+				status(gatewayB, ourController, metav1.ConditionTrue),
+				status(gatewayB, otherController, metav1.ConditionTrue),
+			},
+			ours: []gatewayv1.RouteParentStatus{status(gatewayA, ourController, metav1.ConditionTrue)},
+			want: []gatewayv1.RouteParentStatus{
+				status(gatewayB, otherController, metav1.ConditionTrue),
+				status(gatewayA, ourController, metav1.ConditionTrue),
+			},
+		},
+		{
+			name:       "transition time of an unchanged condition is kept",
+			parentRefs: []gatewayv1.ParentReference{gatewayA},
+			existing:   []gatewayv1.RouteParentStatus{transitioned(status(gatewayA, ourController, metav1.ConditionTrue))},
+			ours:       []gatewayv1.RouteParentStatus{status(gatewayA, ourController, metav1.ConditionTrue)},
+			want:       []gatewayv1.RouteParentStatus{transitioned(status(gatewayA, ourController, metav1.ConditionTrue))},
+		},
+		{
+			name:       "transition time of a changed condition is replaced",
+			parentRefs: []gatewayv1.ParentReference{gatewayA},
+			existing:   []gatewayv1.RouteParentStatus{transitioned(status(gatewayA, ourController, metav1.ConditionFalse))},
+			ours:       []gatewayv1.RouteParentStatus{status(gatewayA, ourController, metav1.ConditionTrue)},
+			want:       []gatewayv1.RouteParentStatus{status(gatewayA, ourController, metav1.ConditionTrue)},
+		},
+		{
+			name:       "duplicate entries of ours are collapsed",
+			parentRefs: []gatewayv1.ParentReference{gatewayA},
+			existing: []gatewayv1.RouteParentStatus{
+				status(gatewayA, ourController, metav1.ConditionFalse),
+				status(gatewayA, ourController, metav1.ConditionFalse),
+			},
+			ours: []gatewayv1.RouteParentStatus{status(gatewayA, ourController, metav1.ConditionTrue)},
+			want: []gatewayv1.RouteParentStatus{status(gatewayA, ourController, metav1.ConditionTrue)},
+		},
+	}
+
+	c := &controller{options: Options{ControllerName: ourController}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpRoute := &gatewayv1.HTTPRoute{
+				Spec: gatewayv1.HTTPRouteSpec{
+					CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: tt.parentRefs},
+				},
+				Status: gatewayv1.HTTPRouteStatus{
+					RouteStatus: gatewayv1.RouteStatus{Parents: tt.existing},
+				},
+			}
+			got := c.mergeRouteParentStatuses(httpRoute, tt.ours)
+			if !equality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("mergeRouteParentStatuses() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/api/v0alpha0"
+	envoydeployer "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/deployer/envoy"
+)
+
+// hasUnmanagedProxies returns whether the proxies of the Gateway are provisioned by someone
+// else. Merged GatewayClasses always deploy the proxies their Gateways share.
+func (c *controller) hasUnmanagedProxies(gateway *gatewayv1.Gateway) bool {
+	gwc, err := c.gateway.gatewayClassLister.Get(string(gateway.Spec.GatewayClassName))
+	if err != nil {
+		gwc = nil
+	}
+	return envoydeployer.IsUnmanaged(gwc, gateway)
+}
+
+// newDeployer returns the Deployer of the proxies of a Gateway that has its own.
+func (c *controller) newDeployer(gateway *gatewayv1.Gateway, config *v0alpha0.XProxyConfigSpec) envoydeployer.Deployer {
+	if c.hasUnmanagedProxies(gateway) {
+		return envoydeployer.NewUnmanagedDeployer(gateway)
+	}
+	return envoydeployer.NewDeployer(
+		c.core.client,
+		c.core.dynamicClient,
+		gateway,
+		c.envoyProxyImage,
+		c.options.ControllerName,
+		config,
+		c.core.configMapLister,
+		c.core.serviceAccountLister,
+		c.core.serviceLister,
+		c.core.deploymentLister,
+		c.core.hpaLister,
+		c.core.pdbLister,
+	)
+}
+
+// setupProxyServiceEventHandlers enqueues the Gateways with unmanaged proxies whose Service
+// changes, so that its addresses are reported in their status.
+func (c *controller) setupProxyServiceEventHandlers(informer eventSource) error {
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueProxyServiceGateways(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.enqueueProxyServiceGateways(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueProxyServiceGateways(obj)
+		},
+	})
+	return err
+}
+
+func (c *controller) enqueueProxyServiceGateways(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return
+	}
+
+	gateways, err := c.gateway.gatewayLister.Gateways(namespace).List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list Gateways", "namespace", namespace)
+		return
+	}
+	for _, gateway := range gateways {
+		if service, ok := envoydeployer.ProxyServiceName(gateway); !ok || service != name || !c.hasUnmanagedProxies(gateway) {
+			continue
+		}
+		gatewayKey := types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}
+		klog.V(4).InfoS("Enqueuing Gateway due to proxy Service change", "gateway", gatewayKey, "service", name)
+		c.gatewayqueue.Add(gatewayKey.String())
+	}
+}
@@ -0,0 +1,129 @@
+package envoy
+
+import (
+	"fmt"
+	"net"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// AddressError reports a Gateway spec.addresses entry that the proxy Service cannot honor.
+// Reason is the Gateway condition reason describing the problem: UnsupportedAddress for the
+// Accepted condition, or AddressNotUsable for the Programmed condition.
+type AddressError struct {
+	Reason  gatewayv1.GatewayConditionReason
+	Message string
+}
+
+func (e *AddressError) Error() string {
+	return e.Message
+}
+
+// serviceAddresses returns the static IPs to request on the proxy Service for the Gateway's
+// spec.addresses. A Service cannot request a hostname, so hostname addresses are unsupported
+// unless they are left empty for the load balancer to assign one.
+func serviceAddresses(gateway *gatewayv1.Gateway, serviceType corev1.ServiceType) (loadBalancerIP string, externalIPs []string, err error) {
+	var ips []string
+	for _, address := range gateway.Spec.Addresses {
+		switch addressType(address.Type) {
+		case gatewayv1.IPAddressType:
+			if address.Value == "" {
+				// The Service is assigned an address dynamically.
+				continue
+			}
+			if net.ParseIP(address.Value) == nil {
+				return "", nil, &AddressError{
+					Reason:  gatewayv1.GatewayReasonAddressNotUsable,
+					Message: fmt.Sprintf("address %q is not a valid IP address", address.Value),
+				}
+			}
+			ips = append(ips, address.Value)
+		case gatewayv1.HostnameAddressType:
+			if address.Value != "" {
+				return "", nil, &AddressError{
+					Reason:  gatewayv1.GatewayReasonUnsupportedAddress,
+					Message: fmt.Sprintf("hostname address %q cannot be requested on the proxy Service", address.Value),
+				}
+			}
+		default:
+			return "", nil, &AddressError{
+				Reason:  gatewayv1.GatewayReasonUnsupportedAddress,
+				Message: fmt.Sprintf("address type %q is not supported", *address.Type),
+			}
+		}
+	}
+
+	if serviceType != corev1.ServiceTypeLoadBalancer {
+		return "", ips, nil
+	}
+	switch len(ips) {
+	case 0:
+		return "", nil, nil
+	case 1:
+		return ips[0], nil, nil
+	default:
+		return "", nil, &AddressError{
+			Reason:  gatewayv1.GatewayReasonAddressNotUsable,
+			Message: fmt.Sprintf("a LoadBalancer Service can only request a single IP address, got %d", len(ips)),
+		}
+	}
+}
+
+// ServiceStatusAddresses returns the addresses the proxy Service exposes the Gateway on: the
+// load balancer ingress addresses for LoadBalancer Services, and the cluster and external IPs
+// otherwise.
+func ServiceStatusAddresses(service *corev1.Service) []gatewayv1.GatewayStatusAddress {
+	var addresses []gatewayv1.GatewayStatusAddress
+	add := func(addressType gatewayv1.AddressType, value string) {
+		address := gatewayv1.GatewayStatusAddress{Type: &addressType, Value: value}
+		if value != "" && !slices.ContainsFunc(addresses, func(a gatewayv1.GatewayStatusAddress) bool { return a.Value == value }) {
+			addresses = append(addresses, address)
+		}
+	}
+
+	if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			add(gatewayv1.IPAddressType, ingress.IP)
+			add(gatewayv1.HostnameAddressType, ingress.Hostname)
+		}
+		return addresses
+	}
+
+	for _, ip := range service.Spec.ClusterIPs {
+		if ip != corev1.ClusterIPNone {
+			add(gatewayv1.IPAddressType, ip)
+		}
+	}
+	for _, ip := range service.Spec.ExternalIPs {
+		add(gatewayv1.IPAddressType, ip)
+	}
+	return addresses
+}
+
+// UnassignedAddresses returns the values of the Gateway's requested addresses that are missing
+// from the assigned addresses.
+func UnassignedAddresses(gateway *gatewayv1.Gateway, assigned []gatewayv1.GatewayStatusAddress) []string {
+	var missing []string
+	for _, address := range gateway.Spec.Addresses {
+		if address.Value == "" {
+			continue
+		}
+		found := slices.ContainsFunc(assigned, func(a gatewayv1.GatewayStatusAddress) bool {
+			return addressType(a.Type) == addressType(address.Type) && a.Value == address.Value
+		})
+		if !found {
+			missing = append(missing, address.Value)
+		}
+	}
+	return missing
+}
+
+// addressType returns the address type, which defaults to IPAddress.
+func addressType(t *gatewayv1.AddressType) gatewayv1.AddressType {
+	if t == nil {
+		return gatewayv1.IPAddressType
+	}
+	return *t
+}
@@ -0,0 +1,173 @@
+package envoy
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestServiceAddresses(t *testing.T) {
+	ip := func(value string) gatewayv1.GatewaySpecAddress {
+		return gatewayv1.GatewaySpecAddress{Type: ptr.To(gatewayv1.IPAddressType), Value: value}
+	}
+	hostname := func(value string) gatewayv1.GatewaySpecAddress {
+		return gatewayv1.GatewaySpecAddress{Type: ptr.To(gatewayv1.HostnameAddressType), Value: value}
+	}
+
+	tests := []struct {
+		name               string
+		addresses          []gatewayv1.GatewaySpecAddress
+		serviceType        corev1.ServiceType
+		wantLoadBalancerIP string
+		wantExternalIPs    []string
+		wantReason         gatewayv1.GatewayConditionReason
+	}{
+		{
+			name:        "no addresses",
+			serviceType: corev1.ServiceTypeLoadBalancer,
+		},
+		{
+			name:               "single IP on a LoadBalancer",
+			addresses:          []gatewayv1.GatewaySpecAddress{ip("10.0.0.1")},
+			serviceType:        corev1.ServiceTypeLoadBalancer,
+			wantLoadBalancerIP: "10.0.0.1",
+		},
+		{
+			name:               "type defaults to IPAddress",
+			addresses:          []gatewayv1.GatewaySpecAddress{{Value: "10.0.0.1"}},
+			serviceType:        corev1.ServiceTypeLoadBalancer,
+			wantLoadBalancerIP: "10.0.0.1",
+		},
+		{
+			name:        "several IPs on a LoadBalancer",
+			addresses:   []gatewayv1.GatewaySpecAddress{ip("10.0.0.1"), ip("10.0.0.2")},
+			serviceType: corev1.ServiceTypeLoadBalancer,
+			wantReason:  gatewayv1.GatewayReasonAddressNotUsable,
+		},
+		{
+			name:            "several IPs on a ClusterIP Service",
+			addresses:       []gatewayv1.GatewaySpecAddress{ip("10.0.0.1"), ip("10.0.0.2")},
+			serviceType:     corev1.ServiceTypeClusterIP,
+			wantExternalIPs: []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:        "empty IP is assigned dynamically",
+			addresses:   []gatewayv1.GatewaySpecAddress{ip("")},
+			serviceType: corev1.ServiceTypeLoadBalancer,
+		},
+		{
+			name:        "invalid IP",
+			addresses:   []gatewayv1.GatewaySpecAddress{ip("not-an-ip")},
+			serviceType: corev1.ServiceTypeLoadBalancer,
+			wantReason:  gatewayv1.GatewayReasonAddressNotUsable,
+		},
+		{
+			name:        "empty hostname is assigned dynamically",
+			addresses:   []gatewayv1.GatewaySpecAddress{hostname("")},
+			serviceType: corev1.ServiceTypeLoadBalancer,
+		},
+		{
+			name:        "hostname cannot be requested",
+			addresses:   []gatewayv1.GatewaySpecAddress{hostname("gateway.example.com")},
+			serviceType: corev1.ServiceTypeLoadBalancer,
+			wantReason:  gatewayv1.GatewayReasonUnsupportedAddress,
+		},
+		{
+			name:        "unsupported type",
+			addresses:   []gatewayv1.GatewaySpecAddress{{Type: ptr.To(gatewayv1.AddressType("example.com/custom")), Value: "custom"}},
+			serviceType: corev1.ServiceTypeLoadBalancer,
+			wantReason:  gatewayv1.GatewayReasonUnsupportedAddress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := &gatewayv1.Gateway{Spec: gatewayv1.GatewaySpec{Addresses: tt.addresses}}
+			loadBalancerIP, externalIPs, err := serviceAddresses(gateway, tt.serviceType)
+
+			var addressErr *AddressError
+			if tt.wantReason != "" {
+				if !errors.As(err, &addressErr) || addressErr.Reason != tt.wantReason {
+					t.Fatalf("serviceAddresses() error = %v, want reason %s", err, tt.wantReason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("serviceAddresses() error = %v", err)
+			}
+			if loadBalancerIP != tt.wantLoadBalancerIP {
+				t.Errorf("loadBalancerIP = %q, want %q", loadBalancerIP, tt.wantLoadBalancerIP)
+			}
+			if !slices.Equal(externalIPs, tt.wantExternalIPs) {
+				t.Errorf("externalIPs = %v, want %v", externalIPs, tt.wantExternalIPs)
+			}
+		})
+	}
+}
+
+func TestUnassignedAddresses(t *testing.T) {
+	loadBalancer := func(ingress ...corev1.LoadBalancerIngress) *corev1.Service {
+		return &corev1.Service{
+			Spec:   corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer, ClusterIP: "10.96.0.10", ClusterIPs: []string{"10.96.0.10"}},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: ingress}},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		addresses []gatewayv1.GatewaySpecAddress
+		service   *corev1.Service
+		want      []string
+	}{
+		{
+			name:      "requested IP assigned",
+			addresses: []gatewayv1.GatewaySpecAddress{{Value: "203.0.113.10"}},
+			service:   loadBalancer(corev1.LoadBalancerIngress{IP: "203.0.113.10"}),
+		},
+		{
+			name:      "requested IP pending",
+			addresses: []gatewayv1.GatewaySpecAddress{{Value: "203.0.113.10"}},
+			service:   loadBalancer(),
+			want:      []string{"203.0.113.10"},
+		},
+		{
+			name:      "dynamic hostname is satisfied by any assignment",
+			addresses: []gatewayv1.GatewaySpecAddress{{Type: ptr.To(gatewayv1.HostnameAddressType)}},
+			service:   loadBalancer(corev1.LoadBalancerIngress{Hostname: "lb.example.com"}),
+		},
+		{
+			name:      "hostname matches by type",
+			addresses: []gatewayv1.GatewaySpecAddress{{Type: ptr.To(gatewayv1.HostnameAddressType), Value: "lb.example.com"}},
+			service:   loadBalancer(corev1.LoadBalancerIngress{IP: "203.0.113.10", Hostname: "lb.example.com"}),
+		},
+		{
+			name:      "cluster IP is not reported for a LoadBalancer",
+			addresses: []gatewayv1.GatewaySpecAddress{{Value: "10.96.0.10"}},
+			service:   loadBalancer(),
+			want:      []string{"10.96.0.10"},
+		},
+		{
+			name:      "external IPs of a ClusterIP Service",
+			addresses: []gatewayv1.GatewaySpecAddress{{Value: "198.51.100.7"}},
+			service: &corev1.Service{Spec: corev1.ServiceSpec{
+				Type:        corev1.ServiceTypeClusterIP,
+				ClusterIPs:  []string{"10.96.0.10"},
+				ExternalIPs: []string{"198.51.100.7"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := &gatewayv1.Gateway{Spec: gatewayv1.GatewaySpec{Addresses: tt.addresses}}
+			got := UnassignedAddresses(gateway, ServiceStatusAddresses(tt.service))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("UnassignedAddresses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
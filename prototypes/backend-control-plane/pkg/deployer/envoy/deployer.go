@@ -5,29 +5,28 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
-	"sync"
-	"time"
 
 	"go.yaml.in/yaml/v3"
-	appsv1 "k8s.io/api/apps/v1"
-	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
-	runtimeutil "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	policyv1listers "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/klog/v2"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/api/v0alpha0"
 	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
 	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/schema/gvk"
 	aigvr "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/schema/gvr"
@@ -41,6 +40,16 @@ type object interface {
 // Patcher is a function that abstracts patching logic. This is largely because client-go fakes do not handle patching
 type patcher func(gvr schema.GroupVersionResource, name string, namespace string, data []byte, subresources ...string) error
 
+// ConflictError reports the objects that Deploy left alone because they already exist without
+// the managed label, and so belong to someone else. Everything else is still deployed.
+type ConflictError struct {
+	Resources []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("refusing to overwrite existing unmanaged resources: %s", strings.Join(e.Resources, ", "))
+}
+
 type Deployer interface {
 	Deploy(ctx context.Context) error
 	NodeID() string
@@ -51,6 +60,8 @@ type listers struct {
 	serviceAccountLister corev1listers.ServiceAccountLister
 	serviceLister        corev1listers.ServiceLister
 	deploymentLister     appsv1listers.DeploymentLister
+	hpaLister            autoscalingv2listers.HorizontalPodAutoscalerLister
+	pdbLister            policyv1listers.PodDisruptionBudgetLister
 }
 
 type deployer struct {
@@ -60,8 +71,16 @@ type deployer struct {
 	patcher      patcher
 	nodeID       string
 	resourceName string
-	image        string
-	namespace    string
+	// cluster is the xDS cluster of the proxies, and owner the owner reference of the deployed
+	// objects.
+	cluster string
+	owner   metav1.OwnerReference
+	image   string
+	// controllerName is the field manager of the applied objects and the value of their
+	// managed label.
+	controllerName gatewayv1.GatewayController
+	config         *v0alpha0.XProxyConfigSpec
+	namespace      string
 }
 
 func NewDeployer(
@@ -69,36 +88,93 @@ func NewDeployer(
 	dynamicClient dynamic.Interface,
 	gateway *gatewayv1.Gateway,
 	image string,
+	controllerName gatewayv1.GatewayController,
+	config *v0alpha0.XProxyConfigSpec,
 	configMapLister corev1listers.ConfigMapLister,
 	serviceAccountLister corev1listers.ServiceAccountLister,
 	serviceLister corev1listers.ServiceLister,
 	deploymentLister appsv1listers.DeploymentLister,
+	hpaLister autoscalingv2listers.HorizontalPodAutoscalerLister,
+	pdbLister policyv1listers.PodDisruptionBudgetLister,
 ) Deployer {
+	return newDeployer(kubeClient, dynamicClient, gateway, image, controllerName, config,
+		configMapLister, serviceAccountLister, serviceLister, deploymentLister, hpaLister, pdbLister)
+}
+
+func newDeployer(
+	kubeClient kubernetes.Interface,
+	dynamicClient dynamic.Interface,
+	gateway *gatewayv1.Gateway,
+	image string,
+	controllerName gatewayv1.GatewayController,
+	config *v0alpha0.XProxyConfigSpec,
+	configMapLister corev1listers.ConfigMapLister,
+	serviceAccountLister corev1listers.ServiceAccountLister,
+	serviceLister corev1listers.ServiceLister,
+	deploymentLister appsv1listers.DeploymentLister,
+	hpaLister autoscalingv2listers.HorizontalPodAutoscalerLister,
+	pdbLister policyv1listers.PodDisruptionBudgetLister,
+) *deployer {
 	return &deployer{
 		kubeClient:   kubeClient,
 		gateway:      gateway,
 		nodeID:       generateNodeID(gateway.Namespace, gateway.Name),
 		resourceName: generateResourceName(gateway.Namespace, gateway.Name),
-		image:        image,
-		namespace:    gateway.Namespace,
+		cluster:      types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}.String(),
+		owner: metav1.OwnerReference{
+			APIVersion: gatewayv1.GroupVersion.String(),
+			Kind:       "Gateway",
+			Name:       gateway.Name,
+			UID:        gateway.UID,
+		},
+		image:          image,
+		controllerName: controllerName,
+		config:         config,
+		namespace:      gateway.Namespace,
 		listers: &listers{
 			configMapLister:      configMapLister,
 			serviceAccountLister: serviceAccountLister,
 			serviceLister:        serviceLister,
 			deploymentLister:     deploymentLister,
+			hpaLister:            hpaLister,
+			pdbLister:            pdbLister,
 		},
 		patcher: func(gvr schema.GroupVersionResource, name string, namespace string, data []byte, subresources ...string) error {
 			c := dynamicClient.Resource(gvr).Namespace(namespace)
 			t := true
 			_, err := c.Patch(context.Background(), name, types.ApplyPatchType, data, metav1.PatchOptions{
 				Force:        &t,
-				FieldManager: constants.EnvoyControllerName,
+				FieldManager: string(controllerName),
 			}, subresources...)
 			return err
 		},
 	}
 }
 
+// ManagedLabelValue returns the value of the ManagedGatewayLabel on the objects deployed by the
+// controller with the given name.
+func ManagedLabelValue(controllerName gatewayv1.GatewayController) string {
+	return strings.ReplaceAll(string(controllerName), "/", "-")
+}
+
+// IsManagedBy returns whether an object was deployed by the controller with the given name. Objects
+// deployed by other instances of the controller, with another controller name, are left alone.
+func IsManagedBy(obj metav1.Object, controllerName gatewayv1.GatewayController) bool {
+	value, ok := obj.GetLabels()[constants.ManagedGatewayLabel]
+	return ok && value == ManagedLabelValue(controllerName)
+}
+
+// NodeIDForGateway returns the xDS node ID of the Envoy proxy deployed for a Gateway:
+// "envoy-proxy-" followed by the first 12 hex characters of the SHA-256 of "<namespace>/<name>".
+func NodeIDForGateway(key types.NamespacedName) string {
+	return generateNodeID(key.Namespace, key.Name)
+}
+
+// ResourceNameForGateway returns the name of the Kubernetes resources deployed for a Gateway.
+func ResourceNameForGateway(key types.NamespacedName) string {
+	return generateResourceName(key.Namespace, key.Name)
+}
+
 func generateNodeID(namespace, name string) string {
 	namespacedName := types.NamespacedName{
 		Namespace: namespace,
@@ -129,7 +205,14 @@ func (d *deployer) Deploy(ctx context.Context) error {
 	logger := klog.FromContext(ctx).WithValues("gateway", klog.KRef(d.gateway.Namespace, d.gateway.Name), "nodeID", d.nodeID)
 	ctx = klog.NewContext(ctx, logger)
 
-	manifests, err := renderBaseTemplateForGateway(d.nodeID, d.gateway, d.image)
+	var appliedReplicas *int32
+	if d.config != nil && d.config.Autoscaling != nil {
+		var err error
+		if appliedReplicas, err = d.appliedReplicas(); err != nil {
+			return err
+		}
+	}
+	manifests, err := renderBaseTemplateForGateway(d.nodeID, d.resourceName, d.cluster, d.owner, d.gateway, d.image, d.config, appliedReplicas)
 	if err != nil {
 		return fmt.Errorf("failed to render base template for gateway %s/%s: %w", d.gateway.Namespace, d.gateway.Name, err)
 	}
@@ -137,17 +220,63 @@ func (d *deployer) Deploy(ctx context.Context) error {
 	return d.apply(ctx, manifests)
 }
 
+// appliedReplicas returns the replica count of the deployed Deployment if the controller's
+// field manager still owns it, or nil if the Deployment does not exist or another manager, such
+// as the HorizontalPodAutoscaler, has taken the field over.
+func (d *deployer) appliedReplicas() (*int32, error) {
+	deployment, err := d.listers.deploymentLister.Deployments(d.namespace).Get(d.resourceName)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range deployment.ManagedFields {
+		if entry.Manager != string(d.controllerName) || entry.Operation != metav1.ManagedFieldsOperationApply ||
+			entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields struct {
+			Spec map[string]json.RawMessage `json:"f:spec"`
+		}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return nil, fmt.Errorf("failed to decode managed fields of deployment %s/%s: %w", d.namespace, d.resourceName, err)
+		}
+		if _, ok := fields.Spec["f:replicas"]; ok {
+			return deployment.Spec.Replicas, nil
+		}
+	}
+	return nil, nil
+}
+
 func (d *deployer) apply(ctx context.Context, manifest []string) error {
+	conflicts := &ConflictError{}
 	for i, resource := range manifest {
 		if err := d.applyOne(ctx, resource); err != nil {
+			var conflictErr *ConflictError
+			if errors.As(err, &conflictErr) {
+				conflicts.Resources = append(conflicts.Resources, conflictErr.Resources...)
+				continue
+			}
 			return fmt.Errorf("error applying resource %d: %w", i, err)
 		}
 	}
 
-	if err := d.waitForGatewayReady(ctx); err != nil {
-		return fmt.Errorf("error waiting for gateway %s/%s to be ready: %w", d.gateway.Namespace, d.gateway.Name, err)
+	// Remove the optional resources the proxy configuration no longer asks for.
+	if d.config == nil || d.config.Autoscaling == nil {
+		if err := d.deleteIfManaged(ctx, aigvr.HorizontalPodAutoscaler); err != nil {
+			return err
+		}
+	}
+	if d.config == nil || d.config.PodDisruptionBudget == nil {
+		if err := d.deleteIfManaged(ctx, aigvr.PodDisruptionBudget); err != nil {
+			return err
+		}
 	}
 
+	if len(conflicts.Resources) > 0 {
+		return conflicts
+	}
 	return nil
 }
 
@@ -166,8 +295,7 @@ func (d *deployer) applyOne(ctx context.Context, resource string) error {
 	unstructuredObj := unstructured.Unstructured{Object: data}
 
 	// set managed label
-	clabel := strings.ReplaceAll(constants.EnvoyControllerName, "/", "-")
-	err = unstructured.SetNestedField(unstructuredObj.Object, clabel, "metadata", "labels", constants.ManagedGatewayLabel)
+	err = unstructured.SetNestedField(unstructuredObj.Object, ManagedLabelValue(d.controllerName), "metadata", "labels", constants.ManagedGatewayLabel)
 	if err != nil {
 		return fmt.Errorf("failed to set managed label: %w", err)
 	}
@@ -180,7 +308,7 @@ func (d *deployer) applyOne(ctx context.Context, resource string) error {
 	canManage, resourceVersion := d.canManage(ctx, gvr, unstructuredObj.GetName(), unstructuredObj.GetNamespace())
 	if !canManage {
 		logger.V(5).Info("skipping %v/%v/%v, already managed", gvr, unstructuredObj.GetName(), unstructuredObj.GetNamespace())
-		return nil
+		return &ConflictError{Resources: []string{fmt.Sprintf("%s %s/%s", gvr.Resource, unstructuredObj.GetNamespace(), unstructuredObj.GetName())}}
 	}
 
 	// Ensure our canManage assertion is not stale
@@ -199,56 +327,27 @@ func (d *deployer) applyOne(ctx context.Context, resource string) error {
 	return nil
 }
 
-func (d *deployer) waitForGatewayReady(ctx context.Context) error {
-	var wg sync.WaitGroup
-	wg.Go(func() {
-		err := d.waitForDeploymenAvailable(ctx)
-		if err != nil {
-			runtimeutil.HandleError(fmt.Errorf("error waiting for deployment available: %w", err))
-		}
-	})
-	wg.Go(func() {
-		err := d.waitForServiceReady(ctx)
-		if err != nil {
-			runtimeutil.HandleError(fmt.Errorf("error waiting for service ready: %w", err))
-		}
-	})
-	wg.Wait()
-	return nil
-}
-
-func (d *deployer) waitForDeploymenAvailable(ctx context.Context) error {
-	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, 1*time.Minute, true, func(ctx context.Context) (bool, error) {
-		dep, err := d.kubeClient.AppsV1().Deployments(d.namespace).Get(ctx, d.resourceName, metav1.GetOptions{})
-		if err != nil {
-			return false, err
-		}
-		for _, cond := range dep.Status.Conditions {
-			if cond.Type == appsv1.DeploymentAvailable && cond.Status == corev1.ConditionTrue {
-				return true, nil
-			}
-		}
-		return false, nil
-	})
-	if err != nil {
-		return fmt.Errorf("waiting for envoy deployment %s to be available: %w", d.resourceName, err)
+// deleteIfManaged deletes the gateway's resource of the given GVR if it exists and is managed by us.
+func (d *deployer) deleteIfManaged(ctx context.Context, gvr schema.GroupVersionResource) error {
+	obj, _ := d.getGVRInstance(ctx, gvr, d.resourceName, d.namespace)
+	if obj == nil {
+		return nil
+	}
+	if !IsManagedBy(obj, d.controllerName) {
+		return nil
 	}
-	return nil
-}
 
-func (d *deployer) waitForServiceReady(ctx context.Context) error {
-	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, 1*time.Minute, true, func(ctx context.Context) (bool, error) {
-		svc, err := d.kubeClient.CoreV1().Services(d.namespace).Get(ctx, d.resourceName, metav1.GetOptions{})
-		if err != nil {
-			return false, err
-		}
-		if svc.Spec.ClusterIP != "" {
-			return true, nil
-		}
-		return false, nil
-	})
-	if err != nil {
-		return fmt.Errorf("waiting for envoy service %s to be ready: %w", d.resourceName, err)
+	var err error
+	switch gvr {
+	case aigvr.HorizontalPodAutoscaler:
+		err = d.kubeClient.AutoscalingV2().HorizontalPodAutoscalers(d.namespace).Delete(ctx, d.resourceName, metav1.DeleteOptions{})
+	case aigvr.PodDisruptionBudget:
+		err = d.kubeClient.PolicyV1().PodDisruptionBudgets(d.namespace).Delete(ctx, d.resourceName, metav1.DeleteOptions{})
+	default:
+		return fmt.Errorf("cannot delete unknown GVR %v", gvr)
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("deleting %v %s/%s: %w", gvr, d.namespace, d.resourceName, err)
 	}
 	return nil
 }
@@ -275,7 +374,7 @@ func unstructuredToGVR(u unstructured.Unstructured) (schema.GroupVersionResource
 }
 
 // canManage checks if a resource we are about to write should be managed by us. If the resource already exists
-// but does not have the ManagedGatewayLabel, we won't overwrite it.
+// but does not have the ManagedGatewayLabel of our controller name, we won't overwrite it.
 // This ensures we don't accidentally take over some resource we weren't supposed to, which could cause outages.
 // Note K8s doesn't have a perfect way to "conditionally SSA", but its close enough (https://github.com/kubernetes/kubernetes/issues/116156).
 func (d *deployer) canManage(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) (bool, string) {
@@ -290,9 +389,8 @@ func (d *deployer) canManage(ctx context.Context, gvr schema.GroupVersionResourc
 		// no object, we can manage it
 		return true, ""
 	}
-	_, managed := obj.GetLabels()[constants.ManagedGatewayLabel]
-	// If object already exists, we can only manage it if it has the label
-	return managed, obj.GetResourceVersion()
+	// If object already exists, we can only manage it if it has our label
+	return IsManagedBy(obj, d.controllerName), obj.GetResourceVersion()
 }
 
 // Returns the object if it exists as well as well as a bool indicating if the gvr was known to us at all
@@ -310,6 +408,10 @@ func (d *deployer) getGVRInstance(ctx context.Context, gvr schema.GroupVersionRe
 		obj, err = d.listers.serviceLister.Services(namespace).Get(name)
 	case aigvr.Deployment:
 		obj, err = d.listers.deploymentLister.Deployments(namespace).Get(name)
+	case aigvr.HorizontalPodAutoscaler:
+		obj, err = d.listers.hpaLister.HorizontalPodAutoscalers(namespace).Get(name)
+	case aigvr.PodDisruptionBudget:
+		obj, err = d.listers.pdbLister.PodDisruptionBudgets(namespace).Get(name)
 	default:
 		logger.V(3).Info("unknown GVR %v", gvr)
 		return nil, false
@@ -325,34 +427,60 @@ func (d *deployer) getGVRInstance(ctx context.Context, gvr schema.GroupVersionRe
 	return obj, true
 }
 
-func DeleteGatewayInfra(ctx context.Context, kubeClient kubernetes.Interface, key types.NamespacedName) error {
-	nodeID := generateNodeID(key.Namespace, key.Name)
-	logger := klog.FromContext(ctx).WithValues("gateway", klog.KRef(key.Namespace, key.Name), "nodeID", nodeID)
+// ManagedResources are the kinds of resources the deployer may create for a Gateway or a
+// GatewayClass.
+var ManagedResources = []schema.GroupVersionResource{
+	aigvr.Deployment,
+	aigvr.HorizontalPodAutoscaler,
+	aigvr.PodDisruptionBudget,
+	aigvr.Service,
+	aigvr.ConfigMap,
+	aigvr.ServiceAccount,
+}
+
+// DeleteGatewayInfra deletes the resources deployed for a Gateway by the controller with the given
+// name. They are found by the managed label and their owner reference to the Gateway rather than by
+// name, so that nothing is left behind regardless of how the resources were named. The owner
+// reference must carry the Gateway's UID, so that the resources of a Gateway recreated under the
+// same name are left alone.
+func DeleteGatewayInfra(ctx context.Context, dynamicClient dynamic.Interface, controllerName gatewayv1.GatewayController, gateway *gatewayv1.Gateway) error {
+	key := types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}
+	logger := klog.FromContext(ctx).WithValues("gateway", klog.KRef(key.Namespace, key.Name))
 	ctx = klog.NewContext(ctx, logger)
 	logger.Info("Deleting gateway infrastructure...")
 
-	err := kubeClient.AppsV1().Deployments(key.Namespace).Delete(ctx, nodeID, metav1.DeleteOptions{})
-	if err != nil && !apierrors.IsNotFound(err) {
-		return fmt.Errorf("deleting deployment %s/%s: %w", key.Namespace, nodeID, err)
-	}
-
-	err = kubeClient.CoreV1().Services(key.Namespace).Delete(ctx, nodeID, metav1.DeleteOptions{})
-	if err != nil && !apierrors.IsNotFound(err) {
-		return fmt.Errorf("deleting service %s/%s: %w", key.Namespace, nodeID, err)
-	}
-
-	err = kubeClient.CoreV1().ConfigMaps(key.Namespace).Delete(ctx, nodeID, metav1.DeleteOptions{})
-	if err != nil && !apierrors.IsNotFound(err) {
-		return fmt.Errorf("deleting configmap %s/%s: %w", key.Namespace, nodeID, err)
-	}
-
-	err = kubeClient.CoreV1().ServiceAccounts(key.Namespace).Delete(ctx, nodeID, metav1.DeleteOptions{})
-	if err != nil && !apierrors.IsNotFound(err) {
-		return fmt.Errorf("deleting serviceaccount %s/%s: %w", key.Namespace, nodeID, err)
+	for _, gvr := range ManagedResources {
+		client := dynamicClient.Resource(gvr).Namespace(key.Namespace)
+		list, err := client.List(ctx, metav1.ListOptions{LabelSelector: labels.Set{
+			constants.ManagedGatewayLabel: ManagedLabelValue(controllerName),
+		}.String()})
+		if err != nil {
+			return fmt.Errorf("listing %s in %s: %w", gvr.Resource, key.Namespace, err)
+		}
+		for _, obj := range list.Items {
+			if owner, ok := GatewayOwner(&obj); !ok || owner.Name != gateway.Name || owner.UID != gateway.UID {
+				continue
+			}
+			err := client.Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("deleting %s %s/%s: %w", gvr.Resource, key.Namespace, obj.GetName(), err)
+			}
+			logger.V(2).Info("Deleted gateway resource", "resource", gvr.Resource, "name", obj.GetName())
+		}
 	}
 
 	logger.Info("Gateway infrastructure deleted.")
-
-	// TODO: Consider removing this data from the xDS cache in some way
 	return nil
 }
+
+// GatewayOwner returns the object's owner reference to a Gateway, if any. The Gateway is in the
+// namespace of the object, and the reference's UID tells it apart from an earlier Gateway of the
+// same name.
+func GatewayOwner(obj metav1.Object) (metav1.OwnerReference, bool) {
+	for _, owner := range obj.GetOwnerReferences() {
+		if owner.APIVersion == gatewayv1.GroupVersion.String() && owner.Kind == "Gateway" {
+			return owner, true
+		}
+	}
+	return metav1.OwnerReference{}, false
+}
@@ -0,0 +1,135 @@
+package envoy
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+	aigvr "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/schema/gvr"
+)
+
+func TestAppliedReplicas(t *testing.T) {
+	const controllerName = "example.com/gateway-controller"
+	replicasFields := &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{},"f:selector":{}}}`)}
+	selectorFields := &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:selector":{}}}`)}
+
+	tests := []struct {
+		name          string
+		managedFields []metav1.ManagedFieldsEntry
+		want          *int32
+	}{
+		{
+			name: "controller owns replicas",
+			managedFields: []metav1.ManagedFieldsEntry{
+				{Manager: controllerName, Operation: metav1.ManagedFieldsOperationApply, FieldsV1: replicasFields},
+			},
+			want: ptr.To[int32](3),
+		},
+		{
+			name: "autoscaler took replicas over",
+			managedFields: []metav1.ManagedFieldsEntry{
+				{Manager: controllerName, Operation: metav1.ManagedFieldsOperationApply, FieldsV1: selectorFields},
+				{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "scale", FieldsV1: replicasFields},
+			},
+		},
+		{
+			name: "another manager applied replicas",
+			managedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "other", Operation: metav1.ManagedFieldsOperationApply, FieldsV1: replicasFields},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := indexer.Add(&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "envoy-gw", ManagedFields: tt.managedFields},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](3)},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			d := &deployer{
+				listers:        &listers{deploymentLister: appsv1listers.NewDeploymentLister(indexer)},
+				namespace:      "default",
+				resourceName:   "envoy-gw",
+				controllerName: controllerName,
+			}
+
+			got, err := d.appliedReplicas()
+			if err != nil {
+				t.Fatalf("appliedReplicas() error = %v", err)
+			}
+			if !ptr.Equal(got, tt.want) {
+				t.Errorf("appliedReplicas() = %v, want %v", ptr.Deref(got, -1), ptr.Deref(tt.want, -1))
+			}
+		})
+	}
+
+	t.Run("deployment does not exist", func(t *testing.T) {
+		d := &deployer{
+			listers:      &listers{deploymentLister: appsv1listers.NewDeploymentLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))},
+			namespace:    "default",
+			resourceName: "envoy-gw",
+		}
+		if got, err := d.appliedReplicas(); got != nil || err != nil {
+			t.Errorf("appliedReplicas() = %v, %v, want nil, nil", got, err)
+		}
+	})
+}
+
+func TestDeleteGatewayInfra(t *testing.T) {
+	const controllerName = "example.com/gateway-controller"
+	configMap := func(name string, uid types.UID) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetNamespace("default")
+		obj.SetName(name)
+		obj.SetLabels(map[string]string{constants.ManagedGatewayLabel: ManagedLabelValue(controllerName)})
+		obj.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: gatewayv1.GroupVersion.String(),
+			Kind:       "Gateway",
+			Name:       "gw",
+			UID:        uid,
+		}})
+		return obj
+	}
+
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, gvr := range ManagedResources {
+		listKinds[gvr] = "List"
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		configMap("current", "uid-2"), configMap("previous", "uid-1"))
+
+	gateway := &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gw", UID: "uid-2"}}
+	if err := DeleteGatewayInfra(context.Background(), client, controllerName, gateway); err != nil {
+		t.Fatalf("DeleteGatewayInfra() error = %v", err)
+	}
+
+	list, err := client.Resource(aigvr.ConfigMap).Namespace("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var remaining []string
+	for _, obj := range list.Items {
+		remaining = append(remaining, obj.GetName())
+	}
+	if !slices.Equal(remaining, []string{"previous"}) {
+		t.Errorf("remaining ConfigMaps = %v, want [previous]", remaining)
+	}
+}
@@ -0,0 +1,111 @@
+package envoy
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	policyv1listers "k8s.io/client-go/listers/policy/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/api/v0alpha0"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+)
+
+// MergedGatewaysNamespace is the namespace of the proxies shared by the Gateways of a
+// GatewayClass.
+const MergedGatewaysNamespace = constants.AIGatewaySystemNamespace
+
+// NewMergedDeployer returns a Deployer for the proxies shared by all Gateways of a GatewayClass
+// that merges its Gateways. They are deployed in MergedGatewaysNamespace, owned by the
+// GatewayClass, and expose every port the Gateways listen on. gateways must be sorted by
+// precedence, since the first Gateway listening on a port decides its protocol.
+func NewMergedDeployer(
+	kubeClient kubernetes.Interface,
+	dynamicClient dynamic.Interface,
+	gatewayClass *gatewayv1.GatewayClass,
+	gateways []*gatewayv1.Gateway,
+	image string,
+	controllerName gatewayv1.GatewayController,
+	config *v0alpha0.XProxyConfigSpec,
+	configMapLister corev1listers.ConfigMapLister,
+	serviceAccountLister corev1listers.ServiceAccountLister,
+	serviceLister corev1listers.ServiceLister,
+	deploymentLister appsv1listers.DeploymentLister,
+	hpaLister autoscalingv2listers.HorizontalPodAutoscalerLister,
+	pdbLister policyv1listers.PodDisruptionBudgetLister,
+) Deployer {
+	d := newDeployer(kubeClient, dynamicClient, mergedGateway(gatewayClass, gateways), image, controllerName, config,
+		configMapLister, serviceAccountLister, serviceLister, deploymentLister, hpaLister, pdbLister)
+	d.nodeID = NodeIDForGatewayClass(gatewayClass.Name)
+	d.resourceName = ResourceNameForGatewayClass(gatewayClass.Name)
+	d.cluster = gatewayClass.Name
+	d.owner = metav1.OwnerReference{
+		APIVersion: gatewayv1.GroupVersion.String(),
+		Kind:       "GatewayClass",
+		Name:       gatewayClass.Name,
+		UID:        gatewayClass.UID,
+	}
+	return d
+}
+
+// mergedGateway returns a Gateway that stands for all the Gateways of the GatewayClass when
+// rendering their shared proxies. It has one listener per port, named after the port since the
+// listener names of different Gateways may clash.
+func mergedGateway(gatewayClass *gatewayv1.GatewayClass, gateways []*gatewayv1.Gateway) *gatewayv1.Gateway {
+	merged := &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: MergedGatewaysNamespace,
+			Name:      gatewayClass.Name,
+		},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: gatewayv1.ObjectName(gatewayClass.Name),
+		},
+	}
+
+	ports := sets.New[gatewayv1.PortNumber]()
+	for _, gateway := range gateways {
+		for _, listener := range gateway.Spec.Listeners {
+			if ports.Has(listener.Port) {
+				continue
+			}
+			ports.Insert(listener.Port)
+			merged.Spec.Listeners = append(merged.Spec.Listeners, gatewayv1.Listener{
+				Name:     gatewayv1.SectionName(fmt.Sprintf("%s-%d", strings.ToLower(string(listener.Protocol)), listener.Port)),
+				Protocol: listener.Protocol,
+				Port:     listener.Port,
+			})
+		}
+	}
+	return merged
+}
+
+// NodeIDForGatewayClass returns the xDS node ID of the proxies shared by the Gateways of a
+// GatewayClass.
+func NodeIDForGatewayClass(name string) string {
+	// Gateway node IDs hash a namespaced name, so an empty namespace never collides with them.
+	return generateNodeID("", name)
+}
+
+// ResourceNameForGatewayClass returns the name of the Kubernetes resources deployed for the
+// Gateways of a GatewayClass.
+func ResourceNameForGatewayClass(name string) string {
+	return "envoy-gatewayclass-" + strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+}
+
+// GatewayClassOwner returns the name of the GatewayClass the object has an owner reference to,
+// if any.
+func GatewayClassOwner(obj metav1.Object) (string, bool) {
+	for _, owner := range obj.GetOwnerReferences() {
+		if owner.APIVersion == gatewayv1.GroupVersion.String() && owner.Kind == "GatewayClass" {
+			return owner.Name, true
+		}
+	}
+	return "", false
+}
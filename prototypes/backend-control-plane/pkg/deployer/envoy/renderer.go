@@ -4,17 +4,55 @@ import (
 	"bufio"
 	"bytes"
 	_ "embed"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"text/template"
 
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/api/v0alpha0"
 	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
 )
 
+// Proxy settings used when neither the GatewayClass nor the Gateway configure them.
+const (
+	defaultReplicas    int32 = 1
+	defaultLogLevel          = v0alpha0.ProxyLogLevelTrace
+	defaultServiceType       = corev1.ServiceTypeLoadBalancer
+)
+
+var defaultResources = corev1.ResourceRequirements{
+	Limits: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2000m"),
+		corev1.ResourceMemory: resource.MustParse("1024Mi"),
+	},
+	Requests: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("100m"),
+		corev1.ResourceMemory: resource.MustParse("128Mi"),
+	},
+}
+
+// defaultAutoscalingMetrics scales the proxies on their average CPU utilization.
+var defaultAutoscalingMetrics = []autoscalingv2.MetricSpec{
+	{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: corev1.ResourceCPU,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: ptr.To[int32](80),
+			},
+		},
+	},
+}
+
 var (
 	//go:embed templates/base.yaml.tpl
 	baseTemplate string
@@ -24,15 +62,52 @@ var (
 )
 
 type baseTemplateParams struct {
-	NodeID                    string
-	ResourceName              string
-	Namespace                 string
-	GatewayName               string
-	GatewayUID                string
+	NodeID       string
+	ResourceName string
+	Namespace    string
+	// OwnerKind, OwnerName and OwnerUID identify the Gateway API object that owns the generated
+	// objects: the Gateway, or the GatewayClass when its Gateways share their proxies.
+	OwnerKind                 string
+	OwnerName                 string
+	OwnerUID                  string
 	EnvoyBootstrapCfgFileName string
 	EnvoyImage                string
-	Ports                     []corev1.ServicePort
-	Bootstrap                 string
+	// Replicas is nil when the Deployment's replica count is left to its autoscaler.
+	Replicas *int32
+	LogLevel string
+	// Resources is the JSON encoding of the proxy container's resources, which is also valid YAML.
+	Resources   string
+	ServiceType string
+	// LoadBalancerIP and ExternalIPs request the Gateway's static IP addresses. ExternalIPs is
+	// JSON encoded, and both are empty when no address is requested.
+	LoadBalancerIP string
+	ExternalIPs    string
+	Ports          []corev1.ServicePort
+	Bootstrap      string
+	// Labels and Annotations are the JSON encodings of the Gateway's infrastructure labels and
+	// annotations, set on every generated object. PodLabels adds the pod selector label to Labels.
+	Labels      string
+	Annotations string
+	PodLabels   string
+	// Autoscaling and PodDisruptionBudget are nil unless the proxy configuration requests them.
+	Autoscaling         *autoscalingTemplateParams
+	PodDisruptionBudget *podDisruptionBudgetTemplateParams
+}
+
+// autoscalingTemplateParams holds the HorizontalPodAutoscaler settings. Metrics and Behavior are
+// JSON encoded, and Behavior is empty if unset.
+type autoscalingTemplateParams struct {
+	MinReplicas int32
+	MaxReplicas int32
+	Metrics     string
+	Behavior    string
+}
+
+// podDisruptionBudgetTemplateParams holds the JSON encoded PodDisruptionBudget settings. Unset
+// fields are empty.
+type podDisruptionBudgetTemplateParams struct {
+	MinAvailable   string
+	MaxUnavailable string
 }
 
 type bootstrapTemplateParams struct {
@@ -53,25 +128,102 @@ func renderBootstrap(cluster, nodeID string) (string, error) {
 	return renderTemplate(fmt.Sprintf("envoy-bootstrap-%s", nodeID), bootstrapTemplate, params)
 }
 
-func renderBaseTemplateForGateway(nodeID string, gateway *gatewayv1.Gateway, image string) ([]string, error) {
-	// Generate a descriptive resource name that includes the gateway name
-	resourceName := generateResourceName(gateway.Namespace, gateway.Name)
-
+// renderBaseTemplateForGateway renders the proxy objects of a Gateway. appliedReplicas is the
+// replica count of the deployed Deployment while the controller still owns that field, and nil
+// otherwise; see the autoscaling case below.
+func renderBaseTemplateForGateway(nodeID, resourceName, cluster string, owner metav1.OwnerReference, gateway *gatewayv1.Gateway, image string, config *v0alpha0.XProxyConfigSpec, appliedReplicas *int32) ([]string, error) {
 	params := baseTemplateParams{
 		NodeID:                    nodeID,
 		ResourceName:              resourceName,
 		Namespace:                 gateway.Namespace,
-		GatewayName:               gateway.Name,
-		GatewayUID:                string(gateway.UID),
+		OwnerKind:                 owner.Kind,
+		OwnerName:                 owner.Name,
+		OwnerUID:                  string(owner.UID),
 		EnvoyBootstrapCfgFileName: constants.EnvoyBootstrapCfgFileName,
 		EnvoyImage:                image,
+		Replicas:                  ptr.To(defaultReplicas),
+		LogLevel:                  string(defaultLogLevel),
+		ServiceType:               string(defaultServiceType),
 		Ports:                     extractServicePorts(*gateway),
 	}
 
-	bootstrap, err := renderBootstrap(types.NamespacedName{
-		Namespace: gateway.Namespace,
-		Name:      gateway.Name,
-	}.String(), nodeID)
+	resources := defaultResources
+	if config != nil {
+		if config.Image != nil {
+			params.EnvoyImage = *config.Image
+		}
+		if config.Replicas != nil {
+			params.Replicas = ptr.To(*config.Replicas)
+		}
+		if config.LogLevel != nil {
+			params.LogLevel = string(*config.LogLevel)
+		}
+		if config.ServiceType != nil {
+			params.ServiceType = string(*config.ServiceType)
+		}
+		if config.Resources != nil {
+			resources = *config.Resources
+		}
+	}
+	loadBalancerIP, externalIPs, err := serviceAddresses(gateway, corev1.ServiceType(params.ServiceType))
+	if err != nil {
+		return nil, err
+	}
+	params.LoadBalancerIP = loadBalancerIP
+	if len(externalIPs) > 0 {
+		encoded, err := json.Marshal(externalIPs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode external IPs: %w", err)
+		}
+		params.ExternalIPs = string(encoded)
+	}
+
+	labels, annotations := infrastructureMetadata(gateway)
+	podLabels := map[string]string{}
+	for k, v := range labels {
+		podLabels[k] = v
+	}
+	// The selector label always wins over an infrastructure label of the same name.
+	podLabels["app"] = resourceName
+
+	// JSON is valid YAML, so these are embedded in the template as flow-style values.
+	for _, field := range []struct {
+		dst   *string
+		value any
+	}{
+		{&params.Resources, resources},
+		{&params.Labels, labels},
+		{&params.Annotations, annotations},
+		{&params.PodLabels, podLabels},
+	} {
+		encoded, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode template parameters: %w", err)
+		}
+		*field.dst = string(encoded)
+	}
+
+	if config != nil && config.Autoscaling != nil {
+		autoscaling, err := renderAutoscalingParams(config.Autoscaling)
+		if err != nil {
+			return nil, err
+		}
+		params.Autoscaling = autoscaling
+		// The HorizontalPodAutoscaler owns the replica count, so the controller stops applying
+		// it. Dropping the field while the controller still owns it would make server-side apply
+		// remove it, resetting the Deployment to a single pod. Until the autoscaler writes the
+		// field and takes ownership of it, the live count is applied instead.
+		params.Replicas = appliedReplicas
+	}
+	if config != nil && config.PodDisruptionBudget != nil {
+		pdb, err := renderPodDisruptionBudgetParams(config.PodDisruptionBudget)
+		if err != nil {
+			return nil, err
+		}
+		params.PodDisruptionBudget = pdb
+	}
+
+	bootstrap, err := renderBootstrap(cluster, nodeID)
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +238,71 @@ func renderBaseTemplateForGateway(nodeID string, gateway *gatewayv1.Gateway, ima
 	return result, nil
 }
 
+func renderAutoscalingParams(autoscaling *v0alpha0.ProxyAutoscaling) (*autoscalingTemplateParams, error) {
+	params := &autoscalingTemplateParams{
+		MinReplicas: 1,
+		MaxReplicas: autoscaling.MaxReplicas,
+	}
+	if autoscaling.MinReplicas != nil {
+		params.MinReplicas = *autoscaling.MinReplicas
+	}
+
+	metrics := autoscaling.Metrics
+	if len(metrics) == 0 {
+		metrics = defaultAutoscalingMetrics
+	}
+	encoded, err := json.Marshal(metrics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode autoscaling metrics: %w", err)
+	}
+	params.Metrics = string(encoded)
+
+	if autoscaling.Behavior != nil {
+		encoded, err := json.Marshal(autoscaling.Behavior)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode autoscaling behavior: %w", err)
+		}
+		params.Behavior = string(encoded)
+	}
+	return params, nil
+}
+
+func renderPodDisruptionBudgetParams(pdb *v0alpha0.ProxyPodDisruptionBudget) (*podDisruptionBudgetTemplateParams, error) {
+	params := &podDisruptionBudgetTemplateParams{}
+	if pdb.MinAvailable != nil {
+		encoded, err := json.Marshal(pdb.MinAvailable)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode minAvailable: %w", err)
+		}
+		params.MinAvailable = string(encoded)
+	}
+	if pdb.MaxUnavailable != nil {
+		encoded, err := json.Marshal(pdb.MaxUnavailable)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode maxUnavailable: %w", err)
+		}
+		params.MaxUnavailable = string(encoded)
+	}
+	return params, nil
+}
+
+// infrastructureMetadata returns the labels and annotations the Gateway requests for its
+// infrastructure. Both maps are non-nil.
+func infrastructureMetadata(gateway *gatewayv1.Gateway) (map[string]string, map[string]string) {
+	labels := map[string]string{}
+	annotations := map[string]string{}
+	if gateway.Spec.Infrastructure == nil {
+		return labels, annotations
+	}
+	for k, v := range gateway.Spec.Infrastructure.Labels {
+		labels[string(k)] = string(v)
+	}
+	for k, v := range gateway.Spec.Infrastructure.Annotations {
+		annotations[string(k)] = string(v)
+	}
+	return labels, annotations
+}
+
 func renderTemplate(name, tpl string, params interface{}) (string, error) {
 	funcMap := template.FuncMap{
 		"indent": func(spaces int, text string) string {
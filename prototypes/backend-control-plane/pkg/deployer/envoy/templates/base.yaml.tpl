@@ -3,11 +3,13 @@ kind: ConfigMap
 metadata:
   name: {{ .ResourceName }}
   namespace: {{ .Namespace }}
+  labels: {{ .Labels }}
+  annotations: {{ .Annotations }}
   ownerReferences:
   - apiVersion: gateway.networking.k8s.io/v1
-    kind: Gateway
-    name: {{ .GatewayName }}
-    uid: {{ .GatewayUID }}
+    kind: {{ .OwnerKind }}
+    name: {{ .OwnerName }}
+    uid: {{ .OwnerUID }}
 data:
   {{.EnvoyBootstrapCfgFileName}}: |
 {{ .Bootstrap | indent 4 }}
@@ -17,44 +19,44 @@ kind: ServiceAccount
 metadata:
   name: {{ .ResourceName }}
   namespace: {{ .Namespace }}
+  labels: {{ .Labels }}
+  annotations: {{ .Annotations }}
   ownerReferences:
   - apiVersion: gateway.networking.k8s.io/v1
-    kind: Gateway
-    name: {{ .GatewayName }}
-    uid: {{ .GatewayUID }}
+    kind: {{ .OwnerKind }}
+    name: {{ .OwnerName }}
+    uid: {{ .OwnerUID }}
 ---
 apiVersion: apps/v1
 kind: Deployment
 metadata:
   name: {{ .ResourceName }}
   namespace: {{ .Namespace }}
+  labels: {{ .Labels }}
+  annotations: {{ .Annotations }}
   ownerReferences:
   - apiVersion: gateway.networking.k8s.io/v1
-    kind: Gateway
-    name: {{ .GatewayName }}
-    uid: {{ .GatewayUID }}
+    kind: {{ .OwnerKind }}
+    name: {{ .OwnerName }}
+    uid: {{ .OwnerUID }}
 spec:
-  replicas: 1
+  {{- with .Replicas }}
+  replicas: {{ . }}
+  {{- end }}
   selector:
     matchLabels:
       app: {{ .ResourceName }}
   template:
     metadata:
-      labels:
-        app: {{ .ResourceName }}
+      labels: {{ .PodLabels }}
+      annotations: {{ .Annotations }}
     spec:
       serviceAccountName: {{ .ResourceName }}
       containers:
         - name: envoy
           image: {{ .EnvoyImage }}
-          command: ["envoy", "-c", "/etc/envoy/{{.EnvoyBootstrapCfgFileName}}", "--log-level", "trace"]
-          resources:
-            limits:
-              cpu: "2000m"
-              memory: "1024Mi"
-            requests:
-              cpu: "100m"
-              memory: "128Mi"
+          command: ["envoy", "-c", "/etc/envoy/{{.EnvoyBootstrapCfgFileName}}", "--log-level", "{{ .LogLevel }}"]
+          resources: {{ .Resources }}
           volumeMounts:
             - name: envoy-bootstrap
               mountPath: /etc/envoy
@@ -72,13 +74,21 @@ kind: Service
 metadata:
   name: {{ .ResourceName }}
   namespace: {{ .Namespace }}
+  labels: {{ .Labels }}
+  annotations: {{ .Annotations }}
   ownerReferences:
   - apiVersion: gateway.networking.k8s.io/v1
-    kind: Gateway
-    name: {{ .GatewayName }}
-    uid: {{ .GatewayUID }}
+    kind: {{ .OwnerKind }}
+    name: {{ .OwnerName }}
+    uid: {{ .OwnerUID }}
 spec:
-  type: LoadBalancer
+  type: {{ .ServiceType }}
+  {{- if .LoadBalancerIP }}
+  loadBalancerIP: {{ .LoadBalancerIP }}
+  {{- end }}
+  {{- if .ExternalIPs }}
+  externalIPs: {{ .ExternalIPs }}
+  {{- end }}
   selector:
     app: {{ .ResourceName }}
   ports:
@@ -88,3 +98,54 @@ spec:
     protocol: TCP
     appProtocol: {{ $val.AppProtocol }}
   {{- end }}
+{{- with .Autoscaling }}
+---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{ $.ResourceName }}
+  namespace: {{ $.Namespace }}
+  labels: {{ $.Labels }}
+  annotations: {{ $.Annotations }}
+  ownerReferences:
+  - apiVersion: gateway.networking.k8s.io/v1
+    kind: {{ $.OwnerKind }}
+    name: {{ $.OwnerName }}
+    uid: {{ $.OwnerUID }}
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: {{ $.ResourceName }}
+  minReplicas: {{ .MinReplicas }}
+  maxReplicas: {{ .MaxReplicas }}
+  metrics: {{ .Metrics }}
+  {{- if .Behavior }}
+  behavior: {{ .Behavior }}
+  {{- end }}
+{{- end }}
+{{- with .PodDisruptionBudget }}
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: {{ $.ResourceName }}
+  namespace: {{ $.Namespace }}
+  labels: {{ $.Labels }}
+  annotations: {{ $.Annotations }}
+  ownerReferences:
+  - apiVersion: gateway.networking.k8s.io/v1
+    kind: {{ $.OwnerKind }}
+    name: {{ $.OwnerName }}
+    uid: {{ $.OwnerUID }}
+spec:
+  selector:
+    matchLabels:
+      app: {{ $.ResourceName }}
+  {{- if .MinAvailable }}
+  minAvailable: {{ .MinAvailable }}
+  {{- end }}
+  {{- if .MaxUnavailable }}
+  maxUnavailable: {{ .MaxUnavailable }}
+  {{- end }}
+{{- end }}
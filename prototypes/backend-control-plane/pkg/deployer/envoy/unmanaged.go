@@ -0,0 +1,56 @@
+package envoy
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+)
+
+// unmanagedDeployer is the Deployer of Gateways whose proxies are provisioned by someone else.
+// It deploys nothing: the proxies only have to identify themselves with its node ID, and the
+// Gateway's namespaced name as their cluster, to be served the Gateway's configuration.
+type unmanagedDeployer struct {
+	gateway types.NamespacedName
+	nodeID  string
+}
+
+// NewUnmanagedDeployer returns a Deployer for a Gateway with unmanaged proxies.
+func NewUnmanagedDeployer(gateway *gatewayv1.Gateway) Deployer {
+	key := types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}
+	return &unmanagedDeployer{
+		gateway: key,
+		nodeID:  NodeIDForGateway(key),
+	}
+}
+
+func (d *unmanagedDeployer) Deploy(ctx context.Context) error {
+	klog.FromContext(ctx).V(2).Info("Skipping deployment of unmanaged proxies", "nodeID", d.nodeID, "cluster", d.gateway.String())
+	return nil
+}
+
+func (d *unmanagedDeployer) NodeID() string {
+	return d.nodeID
+}
+
+// IsUnmanaged returns whether the proxies of the Gateway are unmanaged, as selected by the
+// DeployerAnnotation of the Gateway, or else of its GatewayClass. gatewayClass may be nil.
+func IsUnmanaged(gatewayClass *gatewayv1.GatewayClass, gateway *gatewayv1.Gateway) bool {
+	if deployer, ok := gateway.Annotations[constants.DeployerAnnotation]; ok {
+		return deployer == constants.DeployerUnmanaged
+	}
+	if gatewayClass != nil {
+		return gatewayClass.Annotations[constants.DeployerAnnotation] == constants.DeployerUnmanaged
+	}
+	return false
+}
+
+// ProxyServiceName returns the name of the Service of the unmanaged proxies of the Gateway, if
+// the Gateway names one.
+func ProxyServiceName(gateway *gatewayv1.Gateway) (string, bool) {
+	name := gateway.Annotations[constants.ProxyServiceAnnotation]
+	return name, name != ""
+}
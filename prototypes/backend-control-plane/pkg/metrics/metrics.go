@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the Prometheus metrics of the controller and serves them together
+// with the health and readiness endpoints.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const namespace = "aigateway"
+
+var (
+	// Registry holds every metric exposed by the controller.
+	Registry = prometheus.NewRegistry()
+
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of reconciliations, by resource kind.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"kind"})
+
+	ReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of failed reconciliations, by resource kind.",
+	}, []string{"kind"})
+
+	TranslationDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "translation_duration_seconds",
+		Help:      "Duration of Gateway to xDS translations.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	})
+
+	SnapshotVersion = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "xds_snapshot_version",
+		Help:      "Version of the current xDS snapshot, by node ID.",
+	}, []string{"node_id"})
+
+	SnapshotResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "xds_snapshot_resources",
+		Help:      "Number of resources in the current xDS snapshot, by node ID and resource type.",
+	}, []string{"node_id", "type"})
+
+	XDSStreams = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "xds_connected_streams",
+		Help:      "Number of open xDS streams.",
+	})
+
+	XDSAcks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "xds_acks_total",
+		Help:      "Number of xDS responses acknowledged by proxies, by resource type.",
+	}, []string{"type"})
+
+	XDSNacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "xds_nacks_total",
+		Help:      "Number of xDS responses rejected by proxies, by resource type.",
+	}, []string{"type"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		ReconcileDuration,
+		ReconcileErrors,
+		TranslationDuration,
+		SnapshotVersion,
+		SnapshotResources,
+		XDSStreams,
+		XDSAcks,
+		XDSNacks,
+	)
+	workqueue.SetProvider(workqueueMetricsProvider{})
+}
@@ -0,0 +1,99 @@
+package references
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Kind is the kind of object a Gateway's translation can depend on.
+type Kind string
+
+const (
+	// KindService covers both a Service and the EndpointSlices that belong to it.
+	KindService   Kind = "Service"
+	KindSecret    Kind = "Secret"
+	KindNamespace Kind = "Namespace"
+	KindBackend   Kind = "XBackendDestination"
+)
+
+// Ref identifies an object that was read while translating a Gateway.
+type Ref struct {
+	Kind      Kind
+	Namespace string
+	Name      string
+}
+
+// Tracker records which objects each Gateway read during its last translation, so that a
+// change to one of those objects only re-enqueues the Gateways that actually depend on it.
+type Tracker struct {
+	mu        sync.RWMutex
+	byGateway map[types.NamespacedName]sets.Set[Ref]
+	byRef     map[Ref]sets.Set[types.NamespacedName]
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		byGateway: make(map[types.NamespacedName]sets.Set[Ref]),
+		byRef:     make(map[Ref]sets.Set[types.NamespacedName]),
+	}
+}
+
+// Set replaces the dependencies recorded for a Gateway.
+func (t *Tracker) Set(gateway types.NamespacedName, refs sets.Set[Ref]) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.deleteLocked(gateway)
+	if refs.Len() == 0 {
+		return
+	}
+	t.byGateway[gateway] = refs.Clone()
+	for ref := range refs {
+		gateways, ok := t.byRef[ref]
+		if !ok {
+			gateways = sets.New[types.NamespacedName]()
+			t.byRef[ref] = gateways
+		}
+		gateways.Insert(gateway)
+	}
+}
+
+// Delete forgets all dependencies of a Gateway.
+func (t *Tracker) Delete(gateway types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.deleteLocked(gateway)
+}
+
+func (t *Tracker) deleteLocked(gateway types.NamespacedName) {
+	for ref := range t.byGateway[gateway] {
+		gateways := t.byRef[ref]
+		gateways.Delete(gateway)
+		if gateways.Len() == 0 {
+			delete(t.byRef, ref)
+		}
+	}
+	delete(t.byGateway, gateway)
+}
+
+// GatewaysFor returns the Gateways whose last translation read the given object.
+func (t *Tracker) GatewaysFor(ref Ref) []types.NamespacedName {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.byRef[ref].UnsortedList()
+}
+
+// RefsFor returns the objects of the given kind that the last translation of the Gateway read.
+func (t *Tracker) RefsFor(gateway types.NamespacedName, kind Kind) []Ref {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var refs []Ref
+	for ref := range t.byGateway[gateway] {
+		if ref.Kind == kind {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,124 @@
+package references
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestTracker(t *testing.T) {
+	gatewayA := types.NamespacedName{Namespace: "default", Name: "a"}
+	gatewayB := types.NamespacedName{Namespace: "default", Name: "b"}
+	service := Ref{Kind: KindService, Namespace: "default", Name: "llm"}
+	secret := Ref{Kind: KindSecret, Namespace: "default", Name: "tls"}
+	sameNameBackend := Ref{Kind: KindBackend, Namespace: "default", Name: "llm"}
+
+	type step func(*Tracker)
+	set := func(gateway types.NamespacedName, refs ...Ref) step {
+		return func(tracker *Tracker) { tracker.Set(gateway, sets.New(refs...)) }
+	}
+	deleteGateway := func(gateway types.NamespacedName) step {
+		return func(tracker *Tracker) { tracker.Delete(gateway) }
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+		want  map[Ref][]types.NamespacedName
+	}{
+		{
+			name:  "gateways sharing a ref",
+			steps: []step{set(gatewayA, service, secret), set(gatewayB, service)},
+			want: map[Ref][]types.NamespacedName{
+				service:         {gatewayA, gatewayB},
+				secret:          {gatewayA},
+				sameNameBackend: nil,
+			},
+		},
+		{
+			name:  "set replaces the previous refs",
+			steps: []step{set(gatewayA, service, secret), set(gatewayA, secret)},
+			want: map[Ref][]types.NamespacedName{
+				service: nil,
+				secret:  {gatewayA},
+			},
+		},
+		{
+			name:  "set without refs forgets the gateway",
+			steps: []step{set(gatewayA, service), set(gatewayA)},
+			want:  map[Ref][]types.NamespacedName{service: nil},
+		},
+		{
+			name:  "delete only forgets the deleted gateway",
+			steps: []step{set(gatewayA, service), set(gatewayB, service), deleteGateway(gatewayA)},
+			want:  map[Ref][]types.NamespacedName{service: {gatewayB}},
+		},
+		{
+			name:  "refs are matched by kind",
+			steps: []step{set(gatewayA, sameNameBackend)},
+			want: map[Ref][]types.NamespacedName{
+				service:         nil,
+				sameNameBackend: {gatewayA},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker()
+			for _, step := range tt.steps {
+				step(tracker)
+			}
+			for ref, want := range tt.want {
+				got := tracker.GatewaysFor(ref)
+				slices.SortFunc(got, func(a, b types.NamespacedName) int { return strings.Compare(a.String(), b.String()) })
+				if !slices.Equal(got, want) {
+					t.Errorf("GatewaysFor(%v) = %v, want %v", ref, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTrackerRefsFor(t *testing.T) {
+	gatewayA := types.NamespacedName{Namespace: "default", Name: "a"}
+	gatewayB := types.NamespacedName{Namespace: "default", Name: "b"}
+	llm := Ref{Kind: KindService, Namespace: "default", Name: "llm"}
+	embeddings := Ref{Kind: KindService, Namespace: "models", Name: "embeddings"}
+	secret := Ref{Kind: KindSecret, Namespace: "default", Name: "tls"}
+
+	tracker := NewTracker()
+	tracker.Set(gatewayA, sets.New(llm, embeddings, secret))
+	tracker.Set(gatewayB, sets.New(llm))
+
+	compareRefs := func(a, b Ref) int {
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	}
+	tests := []struct {
+		name    string
+		gateway types.NamespacedName
+		kind    Kind
+		want    []Ref
+	}{
+		{name: "refs of the kind", gateway: gatewayA, kind: KindService, want: []Ref{llm, embeddings}},
+		{name: "other kinds are left out", gateway: gatewayA, kind: KindSecret, want: []Ref{secret}},
+		{name: "refs of other gateways are left out", gateway: gatewayB, kind: KindService, want: []Ref{llm}},
+		{name: "no refs of the kind", gateway: gatewayB, kind: KindSecret},
+		{name: "unknown gateway", gateway: types.NamespacedName{Namespace: "default", Name: "c"}, kind: KindService},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tracker.RefsFor(tt.gateway, tt.kind)
+			slices.SortFunc(got, compareRefs)
+			want := slices.Clone(tt.want)
+			slices.SortFunc(want, compareRefs)
+			if !slices.Equal(got, want) {
+				t.Errorf("RefsFor(%v, %v) = %v, want %v", tt.gateway, tt.kind, got, want)
+			}
+		})
+	}
+}
@@ -36,6 +36,14 @@ func ToGVR(gvk GroupVersionKind) (schema.GroupVersionResource, bool) {
 	case DaemonSet:
 		return gvr.DaemonSet, true
 
+	// Autoscaling API resources
+	case HorizontalPodAutoscaler:
+		return gvr.HorizontalPodAutoscaler, true
+
+	// Policy API resources
+	case PodDisruptionBudget:
+		return gvr.PodDisruptionBudget, true
+
 	// Certificate API resources
 	case ClusterTrustBundle:
 		return gvr.ClusterTrustBundle, true
@@ -6,6 +6,8 @@ var (
 	ConfigMap                = GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
 	Deployment               = GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
 	DaemonSet                = GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}
+	HorizontalPodAutoscaler  = GroupVersionKind{Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"}
+	PodDisruptionBudget      = GroupVersionKind{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"}
 	ClusterTrustBundle       = GroupVersionKind{Group: "certificates.k8s.io", Version: "v1beta1", Kind: "ClusterTrustBundle"}
 	CustomResourceDefinition = GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
 	EndpointSlice            = GroupVersionKind{Group: "discovery.k8s.io", Version: "v1", Kind: "EndpointSlice"}
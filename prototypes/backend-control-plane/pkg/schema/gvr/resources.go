@@ -12,11 +12,13 @@ var (
 	EndpointSlice            = schema.GroupVersionResource{Group: "discovery.k8s.io", Version: "v1", Resource: "endpointslices"}
 	GRPCRoute                = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "grpcroutes"}
 	GatewayClass             = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gatewayclasses"}
+	HorizontalPodAutoscaler  = schema.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}
 	HTTPRoute                = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
 	InferencePool            = schema.GroupVersionResource{Group: "inference.networking.k8s.io", Version: "v1", Resource: "inferencepools"}
 	KubernetesGateway        = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
 	Namespace                = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
 	Pod                      = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	PodDisruptionBudget      = schema.GroupVersionResource{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"}
 	ReferenceGrant           = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1beta1", Resource: "referencegrants"}
 	ReferenceGrant_v1alpha2  = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "referencegrants"}
 	Secret                   = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}
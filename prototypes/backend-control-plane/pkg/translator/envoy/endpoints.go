@@ -0,0 +1,77 @@
+package envoy
+
+import (
+	"sync"
+
+	envoyproxytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// endpointSource describes a ClusterLoadAssignment generated from the EndpointSlices of a Service.
+type endpointSource struct {
+	clusterName string
+	service     types.NamespacedName
+	// portName is the Service port name, matched against the port names of the EndpointSlices.
+	portName string
+	// targetPort is used for EndpointSlices without a matching port. If it is 0, their
+	// endpoints are skipped.
+	targetPort uint32
+}
+
+// endpointIndex remembers the endpointSources produced by the last translation of each Gateway,
+// so that their ClusterLoadAssignments can be regenerated when only the endpoints change.
+type endpointIndex struct {
+	mu        sync.RWMutex
+	byGateway map[types.NamespacedName][]endpointSource
+}
+
+func newEndpointIndex() *endpointIndex {
+	return &endpointIndex{
+		byGateway: make(map[types.NamespacedName][]endpointSource),
+	}
+}
+
+func (i *endpointIndex) set(gateway types.NamespacedName, sources []endpointSource) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.byGateway[gateway] = sources
+}
+
+func (i *endpointIndex) delete(gateway types.NamespacedName) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.byGateway, gateway)
+}
+
+func (i *endpointIndex) get(gateway types.NamespacedName) ([]endpointSource, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	sources, ok := i.byGateway[gateway]
+	return sources, ok
+}
+
+func (t *translator) TranslateEndpointsToXDS(gateway, service types.NamespacedName) ([]envoyproxytypes.Resource, bool) {
+	sources, ok := t.endpoints.get(gateway)
+	if !ok {
+		return nil, false
+	}
+
+	var edsResources []envoyproxytypes.Resource
+	for _, source := range sources {
+		if source.service != service {
+			continue
+		}
+		eds, err := t.generateEDSFromService(source)
+		if err != nil {
+			klog.Errorf("Failed to generate EDS for cluster %s: %v", source.clusterName, err)
+			return nil, false
+		}
+		edsResources = append(edsResources, eds)
+	}
+	return edsResources, true
+}
+
+func (t *translator) ForgetGateway(gateway types.NamespacedName) {
+	t.endpoints.delete(gateway)
+}
@@ -0,0 +1,125 @@
+package envoy
+
+import (
+	"context"
+	"testing"
+
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
+
+	aigatewaylisters "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/k8s/client/listers/api/v0alpha0"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/references"
+)
+
+// TestTranslateEndpointsToXDS checks that the endpoints of a translated Gateway are regenerated
+// from the current EndpointSlices of one Service, without translating the Gateway again.
+func TestTranslateEndpointsToXDS(t *testing.T) {
+	gateway := &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gateway"},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: "wg-ai-gateway",
+			Listeners:        []gatewayv1.Listener{{Name: "http", Protocol: gatewayv1.HTTPProtocolType, Port: 8080}},
+		},
+	}
+	backendRef := func(name string) gatewayv1.HTTPBackendRef {
+		return gatewayv1.HTTPBackendRef{BackendRef: gatewayv1.BackendRef{
+			BackendObjectReference: gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(name), Port: ptr.To(gatewayv1.PortNumber(80))},
+		}}
+	}
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "route"},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{{Name: "gateway"}}},
+			Rules: []gatewayv1.HTTPRouteRule{
+				{BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("first")}},
+				{BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("second")}},
+			},
+		},
+	}
+	service := func(name string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8080)}}},
+		}
+	}
+	endpointSlice := func(service string, addresses ...string) *discoveryv1.EndpointSlice {
+		slice := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      service + "-abcde",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports:       []discoveryv1.EndpointPort{{Name: ptr.To("http"), Port: ptr.To(int32(8080))}},
+		}
+		for _, address := range addresses {
+			slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{Addresses: []string{address}})
+		}
+		return slice
+	}
+
+	endpointSlices := newTestIndexer(t, endpointSlice("first", "10.0.0.1"), endpointSlice("second", "10.0.1.1"))
+	tr := New(
+		"sigs.k8s.io/wg-ai-gateway-envoy-controller",
+		nil,
+		nil,
+		corev1listers.NewNamespaceLister(newTestIndexer(t)),
+		corev1listers.NewServiceLister(newTestIndexer(t, service("first"), service("second"))),
+		corev1listers.NewSecretLister(newTestIndexer(t)),
+		discoverylisters.NewEndpointSliceLister(endpointSlices),
+		gatewaylisters.NewGatewayLister(newTestIndexer(t, gateway)),
+		gatewaylisters.NewHTTPRouteLister(newTestIndexer(t, route)),
+		aigatewaylisters.NewXBackendDestinationLister(newTestIndexer(t)),
+		references.NewTracker(),
+		nil,
+	)
+
+	gatewayKey := types.NamespacedName{Namespace: "default", Name: "gateway"}
+	first := types.NamespacedName{Namespace: "default", Name: "first"}
+	if _, ok := tr.TranslateEndpointsToXDS(gatewayKey, first); ok {
+		t.Fatalf("TranslateEndpointsToXDS() before translating the Gateway succeeded")
+	}
+
+	resources, _, _, err := tr.TranslateGatewayAndReferencesToXDS(context.Background(), gateway)
+	if err != nil {
+		t.Fatalf("TranslateGatewayAndReferencesToXDS() error = %v", err)
+	}
+	if got := len(resources[resourcev3.EndpointType]); got != 2 {
+		t.Fatalf("translated %d ClusterLoadAssignments, want 2", got)
+	}
+
+	if err := endpointSlices.Update(endpointSlice("first", "10.0.0.1", "10.0.0.2")); err != nil {
+		t.Fatal(err)
+	}
+	endpoints, ok := tr.TranslateEndpointsToXDS(gatewayKey, first)
+	if !ok {
+		t.Fatalf("TranslateEndpointsToXDS() failed")
+	}
+	if len(endpoints) != 1 {
+		t.Fatalf("TranslateEndpointsToXDS() returned %d ClusterLoadAssignments, want only the one of Service first", len(endpoints))
+	}
+	var addresses []string
+	for _, locality := range endpoints[0].(*endpointv3.ClusterLoadAssignment).GetEndpoints() {
+		for _, lbEndpoint := range locality.GetLbEndpoints() {
+			addresses = append(addresses, lbEndpoint.GetEndpoint().GetAddress().GetSocketAddress().GetAddress())
+		}
+	}
+	if len(addresses) != 2 {
+		t.Errorf("ClusterLoadAssignment addresses = %v, want the two endpoints of the updated EndpointSlice", addresses)
+	}
+
+	tr.ForgetGateway(gatewayKey)
+	if _, ok := tr.TranslateEndpointsToXDS(gatewayKey, first); ok {
+		t.Errorf("TranslateEndpointsToXDS() after ForgetGateway succeeded")
+	}
+}
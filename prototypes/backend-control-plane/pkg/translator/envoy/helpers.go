@@ -12,6 +12,7 @@ import (
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	fileaccesslogv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/file/v3"
 	routerv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
+	statefulsessionv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/stateful_session/v3"
 	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	transport_socketsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
@@ -22,11 +23,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/api/v0alpha0"
 	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
 	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/protoconv"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/references"
 )
 
 // buildClustersFromBackends creates Envoy clusters from Backend resources
@@ -36,7 +39,11 @@ func (t *translator) buildClustersFromBackends(backends []RouteBackend) ([]*clus
 
 	for _, backend := range backends {
 		if len(backend.Ports) == 0 {
-			return nil, fmt.Errorf("backend %s has no ports defined", backend.String())
+			return nil, &BackendError{
+				Source: backend.Source,
+				Reason: BackendReasonInvalid,
+				Err:    fmt.Errorf("backend %s has no ports defined", backend.String()),
+			}
 		}
 
 		// Create one cluster per port
@@ -55,7 +62,11 @@ func (t *translator) buildClustersFromBackends(backends []RouteBackend) ([]*clus
 				cluster.ClusterDiscoveryType = &clusterv3.Cluster_Type{Type: clusterv3.Cluster_LOGICAL_DNS}
 				cluster.DnsLookupFamily = clusterv3.Cluster_V4_ONLY
 				if backend.Hostname == "" {
-					return nil, fmt.Errorf("backend %s has type FQDN but no FQDN configuration", backend.String())
+					return nil, &BackendError{
+						Source: backend.Source,
+						Reason: BackendReasonInvalid,
+						Err:    fmt.Errorf("backend %s has type FQDN but no FQDN configuration", backend.String()),
+					}
 				}
 				cluster.LoadAssignment = t.createClusterLoadAssignment(clusterName, backend.Hostname, port.Number)
 
@@ -78,8 +89,12 @@ func (t *translator) buildClustersFromBackends(backends []RouteBackend) ([]*clus
 			if port.TLS != nil && port.TLS.Mode != v0alpha0.BackendTLSModeNone {
 				transportSocket, err := t.buildUpstreamTransportSocket(port.TLS, backend.Hostname, port.Protocol, backend.Source.Namespace)
 				if err != nil {
-					return nil, fmt.Errorf("failed to build TLS transport socket for backend %s port %d: %w",
-						backend.String(), port.Number, err)
+					return nil, &BackendError{
+						Source: backend.Source,
+						Reason: BackendReasonInvalidTLS,
+						Err: fmt.Errorf("failed to build TLS transport socket for backend %s port %d: %w",
+							backend.String(), port.Number, err),
+					}
 				}
 				cluster.TransportSocket = transportSocket
 			}
@@ -224,6 +239,7 @@ func (t *translator) resolveCABundle(refs []gatewayv1.ObjectReference, defaultNa
 		if ref.Namespace != nil {
 			namespace = string(*ref.Namespace)
 		}
+		t.recordRef(references.KindSecret, namespace, string(ref.Name))
 		secret, err := t.secretLister.Secrets(namespace).Get(string(ref.Name))
 		if err != nil {
 			return nil, fmt.Errorf("failed to get CA bundle secret %s/%s: %w", namespace, ref.Name, err)
@@ -247,6 +263,7 @@ func (t *translator) resolveClientCertificate(ref *gatewayv1.SecretObjectReferen
 	if ref.Namespace != nil {
 		namespace = string(*ref.Namespace)
 	}
+	t.recordRef(references.KindSecret, namespace, string(ref.Name))
 	secret, err := t.secretLister.Secrets(namespace).Get(string(ref.Name))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client certificate secret %s/%s: %w", namespace, ref.Name, err)
@@ -280,6 +297,15 @@ func (t *translator) translateListenerToFilterChain(gateway *gatewayv1.Gateway,
 		},
 		// Add HTTP filters - router is required for request routing
 		HttpFilters: []*hcmv3.HttpFilter{
+			// The stateful session filter is a no-op unless a route enables it through
+			// per-route config, which is how HTTPRoute sessionPersistence is applied.
+			{
+				Name: constants.StatefulSessionFilterName,
+				ConfigType: &hcmv3.HttpFilter_TypedConfig{
+					TypedConfig: protoconv.MessageToAny(&statefulsessionv3.StatefulSession{}),
+				},
+			},
+			backendPathRewriteFilter(),
 			{
 				Name: wellknown.Router,
 				ConfigType: &hcmv3.HttpFilter_TypedConfig{
@@ -367,24 +393,33 @@ func (t *translator) createEnvoyAddress(port uint32) *corev3.Address {
 	}
 }
 
-// generateEDSFromService creates EDS endpoints for a Kubernetes service using EndpointSlices
-func (t *translator) generateEDSFromService(serviceName, serviceNamespace string, servicePort uint32) (*endpointv3.ClusterLoadAssignment, error) {
+// generateEDSFromService creates EDS endpoints for a Kubernetes service using EndpointSlices.
+// The endpoint port is resolved per EndpointSlice, so named targetPorts and pods listening on
+// different port numbers are handled.
+func (t *translator) generateEDSFromService(source endpointSource) (*endpointv3.ClusterLoadAssignment, error) {
+	serviceName, serviceNamespace := source.service.Name, source.service.Namespace
+
 	// Get EndpointSlices for the service
 	selector := labels.Set(map[string]string{
 		discoveryv1.LabelServiceName: serviceName,
 	}).AsSelector()
 
+	t.recordRef(references.KindService, serviceNamespace, serviceName)
 	endpointSlices, err := t.endpointSliceLister.EndpointSlices(serviceNamespace).List(selector)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list EndpointSlices for service %s/%s: %w", serviceNamespace, serviceName, err)
 	}
 
-	clusterName := fmt.Sprintf(constants.ClusterNameFormat, serviceNamespace, serviceName, servicePort)
-
 	var lbEndpoints []*endpointv3.LbEndpoint
 
 	// Iterate through all EndpointSlices for this service
 	for _, es := range endpointSlices {
+		endpointPort, ok := endpointSlicePort(es, source.portName, source.targetPort)
+		if !ok {
+			klog.V(4).Infof("EndpointSlice %s/%s has no port named %q, skipping", es.Namespace, es.Name, source.portName)
+			continue
+		}
+
 		// Process endpoints in this slice
 		for _, endpoint := range es.Endpoints {
 			// Skip endpoints that are not ready
@@ -402,7 +437,7 @@ func (t *translator) generateEDSFromService(serviceName, serviceNamespace string
 									SocketAddress: &corev3.SocketAddress{
 										Address: address,
 										PortSpecifier: &corev3.SocketAddress_PortValue{
-											PortValue: servicePort,
+											PortValue: endpointPort,
 										},
 									},
 								},
@@ -422,7 +457,7 @@ func (t *translator) generateEDSFromService(serviceName, serviceNamespace string
 	}
 
 	return &endpointv3.ClusterLoadAssignment{
-		ClusterName: clusterName,
+		ClusterName: source.clusterName,
 		Endpoints: []*endpointv3.LocalityLbEndpoints{
 			{
 				LbEndpoints: lbEndpoints,
@@ -430,3 +465,20 @@ func (t *translator) generateEDSFromService(serviceName, serviceNamespace string
 		},
 	}, nil
 }
+
+// endpointSlicePort returns the port the endpoints of an EndpointSlice listen on for the Service
+// port with the given name. EndpointSlices name their ports after the Service ports, with an
+// unnamed port matching an unnamed Service port. If the slice has no such port, targetPort is
+// used, unless it is 0.
+func endpointSlicePort(es *discoveryv1.EndpointSlice, portName string, targetPort uint32) (uint32, bool) {
+	for _, port := range es.Ports {
+		name := ""
+		if port.Name != nil {
+			name = *port.Name
+		}
+		if name == portName && port.Port != nil {
+			return uint32(*port.Port), true
+		}
+	}
+	return targetPort, targetPort != 0
+}
@@ -0,0 +1,74 @@
+package envoy
+
+import (
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestEndpointSlicePort(t *testing.T) {
+	slice := func(ports ...discoveryv1.EndpointPort) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{Ports: ports}
+	}
+
+	tests := []struct {
+		name       string
+		es         *discoveryv1.EndpointSlice
+		portName   string
+		targetPort uint32
+		want       uint32
+		wantOK     bool
+	}{
+		{
+			name:       "named port",
+			es:         slice(discoveryv1.EndpointPort{Name: ptr.To("metrics"), Port: ptr.To(int32(9090))}, discoveryv1.EndpointPort{Name: ptr.To("http"), Port: ptr.To(int32(8080))}),
+			portName:   "http",
+			targetPort: 80,
+			want:       8080,
+			wantOK:     true,
+		},
+		{
+			name:   "unnamed port matches an unnamed Service port",
+			es:     slice(discoveryv1.EndpointPort{Port: ptr.To(int32(8080))}),
+			want:   8080,
+			wantOK: true,
+		},
+		{
+			name:   "empty name matches an unnamed port",
+			es:     slice(discoveryv1.EndpointPort{Name: ptr.To(""), Port: ptr.To(int32(8080))}),
+			want:   8080,
+			wantOK: true,
+		},
+		{
+			name:       "unnamed port does not match a named Service port",
+			es:         slice(discoveryv1.EndpointPort{Port: ptr.To(int32(8080))}),
+			portName:   "http",
+			targetPort: 80,
+			want:       80,
+			wantOK:     true,
+		},
+		{
+			name:       "port without a number falls back to the target port",
+			es:         slice(discoveryv1.EndpointPort{Name: ptr.To("http")}),
+			portName:   "http",
+			targetPort: 80,
+			want:       80,
+			wantOK:     true,
+		},
+		{
+			name:     "no port and no target port",
+			es:       slice(),
+			portName: "http",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := endpointSlicePort(tt.es, tt.portName, tt.targetPort)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("endpointSlicePort() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
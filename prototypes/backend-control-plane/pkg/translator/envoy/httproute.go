@@ -5,15 +5,24 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	statefulsessionv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/stateful_session/v3"
+	cookiesessionv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/http/stateful_session/cookie/v3"
+	headersessionv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/http/stateful_session/header/v3"
+	httpv3 "github.com/envoyproxy/go-control-plane/envoy/type/http/v3"
 	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
-	v1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
@@ -21,6 +30,23 @@ import (
 	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/api/v0alpha0"
 	aigatewaylisters "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/k8s/client/listers/api/v0alpha0"
 	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/protoconv"
+)
+
+// methodHeaderName is the pseudo-header Envoy exposes the request method under.
+const methodHeaderName = ":method"
+
+// supportedHTTPMethods are the methods that can be used in an HTTPRouteMatch. CONNECT is left
+// out: Envoy only routes CONNECT requests through a connect_matcher, never by a :method match.
+var supportedHTTPMethods = sets.New(
+	gatewayv1.HTTPMethodGet,
+	gatewayv1.HTTPMethodHead,
+	gatewayv1.HTTPMethodPost,
+	gatewayv1.HTTPMethodPut,
+	gatewayv1.HTTPMethodDelete,
+	gatewayv1.HTTPMethodOptions,
+	gatewayv1.HTTPMethodTrace,
+	gatewayv1.HTTPMethodPatch,
 )
 
 // ControllerError represents a structured error that can be used to set failure conditions
@@ -32,9 +58,12 @@ type ControllerError struct {
 // RouteBackend is an abstraction for a backend used in routing
 // TODO: Refactor this into a proper domain model representation
 type RouteBackend struct {
-	Source         *RouteBackendSource
-	Hostname       string
-	Ports          []RouteBackendPort
+	Source   *RouteBackendSource
+	Hostname string
+	Ports    []RouteBackendPort
+	// Service is the Kubernetes Service whose EndpointSlices provide the endpoints. Only
+	// populated for backends with the EDS resolution type.
+	Service        types.NamespacedName
 	ResolutionType RouteBackendResolutionType
 }
 
@@ -46,10 +75,17 @@ const (
 )
 
 type RouteBackendPort struct {
-	Number       uint32
-	FrontendPort uint32 // only populated for k8s services
-	Protocol     v0alpha0.BackendProtocol
-	TLS          *v0alpha0.BackendTLS
+	// Number is the port clusters are keyed by: the Service port for k8s services, the
+	// destination port otherwise.
+	Number uint32
+	// Name is the name of the Service port, which EndpointSlices use to name the endpoint
+	// port. Only populated for k8s services.
+	Name string
+	// TargetPort is the port endpoints listen on when EndpointSlices don't carry a matching
+	// port. It is 0 for k8s services with a named targetPort.
+	TargetPort uint32
+	Protocol   v0alpha0.BackendProtocol
+	TLS        *v0alpha0.BackendTLS
 }
 
 type RouteBackendSource struct {
@@ -76,14 +112,51 @@ func (rb *RouteBackend) String() string {
 func (e *ControllerError) Error() string {
 	return e.Message
 }
+
+const (
+	// BackendReasonInvalid is the BackendError reason for a backend whose configuration cannot
+	// be translated.
+	BackendReasonInvalid = "InvalidBackend"
+	// BackendReasonInvalidTLS is the BackendError reason for a backend whose TLS configuration,
+	// such as its CA bundle or client certificate Secrets, cannot be resolved.
+	BackendReasonInvalidTLS = "InvalidTLSConfig"
+)
+
+// BackendError reports a backend that fails the translation of the Gateways routing to it.
+type BackendError struct {
+	Source *RouteBackendSource
+	Reason string
+	Err    error
+}
+
+func (e *BackendError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BackendError) Unwrap() error {
+	return e.Err
+}
+
+// translateHTTPRouteToEnvoyRoutes translates the rules of an HTTPRoute into Envoy routes. It
+// returns the backends they send traffic to, the ResolvedRefs condition of the route, and an
+// error if the route must not be accepted. A route using values that cannot be supported is not
+// programmed at all, so no routes or backends are returned with the error. A route with
+// backendRef filters that cannot be applied is still programmed, with the share of those
+// backendRefs answered with a 500.
 func translateHTTPRouteToEnvoyRoutes(
 	httpRoute *gatewayv1.HTTPRoute,
 	serviceLister corev1listers.ServiceLister,
 	backendLister aigatewaylisters.XBackendDestinationLister,
-) ([]*routev3.Route, []RouteBackend, metav1.Condition) {
+	watchedNamespaces sets.Set[string],
+) ([]*routev3.Route, []RouteBackend, metav1.Condition, *ControllerError) {
 	var envoyRoutes []*routev3.Route
 	var allValidBackends []RouteBackend
-	overallCondition := createSuccessCondition(httpRoute.Generation)
+	// refErrs collects the problems of every rule, which are folded into the ResolvedRefs condition.
+	var refErrs []*ControllerError
+	// rejectErrs collects the values that cannot be supported, any of which rejects the route.
+	var rejectErrs []*ControllerError
+	// filterErrs collects the backendRefs whose filters cannot be applied.
+	var filterErrs []*ControllerError
 
 	for ruleIndex, rule := range httpRoute.Spec.Rules {
 		// These are the different operations that an HTTPRoute rule can specify
@@ -123,10 +196,34 @@ func translateHTTPRouteToEnvoyRoutes(
 			}
 		}
 
+		// Session persistence applies to every forwarding route generated from this rule. Settings
+		// we cannot honor reject the route rather than silently serving it without them.
+		sessionPersistence := rule.SessionPersistence
+		if sessionPersistence != nil {
+			err := validateSessionPersistence(sessionPersistence)
+			if err == nil && weightedBackendRefs(rule.BackendRefs) > 1 {
+				// The stateful session filter only pins the host within the cluster the route
+				// picked, and a weighted cluster is picked again on every request.
+				err = fmt.Errorf("sessionPersistence is not supported on rules that split traffic across several backendRefs")
+			}
+			if err != nil {
+				rejectErrs = append(rejectErrs, &ControllerError{
+					Reason:  string(gatewayv1.RouteReasonUnsupportedValue),
+					Message: fmt.Sprintf("Rule %d: %v", ruleIndex, err),
+				})
+				sessionPersistence = nil
+			}
+		}
+
 		buildRoutesForRule := func(match gatewayv1.HTTPRouteMatch, matchIndex int) {
-			routeMatch, matchCondition := translateHTTPRouteMatch(match, httpRoute.Generation)
-			if matchCondition.Status == metav1.ConditionFalse {
-				overallCondition = matchCondition
+			// A match that cannot be translated rejects the route: programming the rest of the
+			// rule without it would match traffic the route is not meant for.
+			routeMatch, err := translateHTTPRouteMatch(match)
+			if err != nil {
+				rejectErrs = append(rejectErrs, &ControllerError{
+					Reason:  string(gatewayv1.RouteReasonUnsupportedValue),
+					Message: fmt.Sprintf("Rule %d: %v", ruleIndex, err),
+				})
 				return
 			}
 
@@ -146,23 +243,26 @@ func translateHTTPRouteToEnvoyRoutes(
 				}
 			} else {
 				// Build the forwarding action with backend clusters
-				routeAction, validBackends, err := buildHTTPRouteAction(
+				routeAction, validBackends, actionErrs, actionFilterErrs := buildHTTPRouteAction(
 					httpRoute.Namespace,
 					rule.BackendRefs,
+					match,
+					urlRewriteAction != nil && (urlRewriteAction.RegexRewrite != nil || urlRewriteAction.PrefixRewrite != ""),
 					serviceLister,
 					backendLister,
+					watchedNamespaces,
 				)
-				var controllerErr *ControllerError
-				if errors.As(err, &controllerErr) {
-					overallCondition = createFailureCondition(gatewayv1.RouteConditionReason(controllerErr.Reason), controllerErr.Message, httpRoute.Generation)
+				refErrs = append(refErrs, actionErrs...)
+				filterErrs = append(filterErrs, actionFilterErrs...)
+				allValidBackends = append(allValidBackends, validBackends...)
+				if routeAction == nil {
 					envoyRoute.Action = &routev3.Route_DirectResponse{
 						DirectResponse: &routev3.DirectResponseAction{Status: 500},
 					}
-					// Skip further processing for this route if backends are invalid.
+					// Skip further processing for this route if no backend can receive traffic.
 					envoyRoutes = append(envoyRoutes, envoyRoute)
 					return
 				}
-				allValidBackends = append(allValidBackends, validBackends...)
 
 				// If a URLRewrite filter was present, merge its properties into the RouteAction.
 				if urlRewriteAction != nil {
@@ -186,6 +286,21 @@ func translateHTTPRouteToEnvoyRoutes(
 				envoyRoute.Action = &routev3.Route_Route{
 					Route: routeAction,
 				}
+
+				if sessionPersistence != nil {
+					sessionName := fmt.Sprintf(constants.SessionNameFormat, httpRoute.Namespace, httpRoute.Name, ruleIndex)
+					perRouteConfig, err := translateSessionPersistence(sessionPersistence, sessionName, match)
+					if err != nil {
+						rejectErrs = append(rejectErrs, &ControllerError{
+							Reason:  string(gatewayv1.RouteReasonUnsupportedValue),
+							Message: fmt.Sprintf("Rule %d: %v", ruleIndex, err),
+						})
+					} else {
+						envoyRoute.TypedPerFilterConfig = map[string]*anypb.Any{
+							constants.StatefulSessionFilterName: perRouteConfig,
+						}
+					}
+				}
 			}
 			envoyRoutes = append(envoyRoutes, envoyRoute)
 		}
@@ -202,7 +317,14 @@ func translateHTTPRouteToEnvoyRoutes(
 	// Sort routes by Gateway API precedence rules
 	sortRoutes(envoyRoutes)
 
-	return envoyRoutes, allValidBackends, overallCondition
+	resolvedRefsCondition := createSuccessCondition(httpRoute.Generation)
+	if err := joinControllerErrors(refErrs); err != nil {
+		resolvedRefsCondition = createFailureCondition(gatewayv1.RouteConditionReason(err.Reason), err.Message, httpRoute.Generation)
+	}
+	if err := joinControllerErrors(rejectErrs); err != nil {
+		return nil, nil, resolvedRefsCondition, err
+	}
+	return envoyRoutes, allValidBackends, resolvedRefsCondition, joinControllerErrors(filterErrs)
 }
 
 func translateRequestRedirectFilter(requestRedirect *gatewayv1.HTTPRequestRedirectFilter) *routev3.RedirectAction {
@@ -297,6 +419,109 @@ func translateURLRewriteFilter(urlRewrite *gatewayv1.HTTPURLRewriteFilter) *rout
 	return routeAction
 }
 
+// validateSessionPersistence rejects session persistence settings that cannot be expressed
+// with Envoy's stateful session filter.
+func validateSessionPersistence(sp *gatewayv1.SessionPersistence) error {
+	if sp.IdleTimeout != nil {
+		// Envoy only re-issues the session cookie or header when the selected host changes,
+		// so there is no way to slide the expiry forward on activity.
+		return fmt.Errorf("sessionPersistence.idleTimeout is not supported")
+	}
+	if sp.AbsoluteTimeout == nil {
+		return nil
+	}
+	if _, err := time.ParseDuration(string(*sp.AbsoluteTimeout)); err != nil {
+		return fmt.Errorf("invalid sessionPersistence.absoluteTimeout %q: %w", *sp.AbsoluteTimeout, err)
+	}
+	if sp.Type != nil && *sp.Type == gatewayv1.HeaderBasedSessionPersistence {
+		// The session header only carries the selected host, with no expiry.
+		return fmt.Errorf("sessionPersistence.absoluteTimeout is not supported for header-based sessions")
+	}
+	if cookieLifetimeType(sp) == gatewayv1.SessionCookieLifetimeType {
+		// Envoy sets the expiry of the session in the cookie value and as its Max-Age together,
+		// so a timeout always turns the cookie into a permanent one.
+		return fmt.Errorf("sessionPersistence.absoluteTimeout is only supported with the Permanent cookie lifetimeType")
+	}
+	return nil
+}
+
+// weightedBackendRefs returns the number of backendRefs that receive a share of the traffic.
+func weightedBackendRefs(backendRefs []gatewayv1.HTTPBackendRef) int {
+	count := 0
+	for _, backendRef := range backendRefs {
+		if backendRef.Weight == nil || *backendRef.Weight > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// cookieLifetimeType returns the lifetime type of a session cookie, which defaults to Session.
+func cookieLifetimeType(sp *gatewayv1.SessionPersistence) gatewayv1.CookieLifetimeType {
+	if sp.CookieConfig != nil && sp.CookieConfig.LifetimeType != nil {
+		return *sp.CookieConfig.LifetimeType
+	}
+	return gatewayv1.SessionCookieLifetimeType
+}
+
+// translateSessionPersistence builds the per-route stateful session config for a rule's
+// session persistence settings, which must have passed validateSessionPersistence. Cookie-based
+// sessions encode the selected upstream host in a cookie scoped to the route's path; header-based
+// sessions use a request/response header.
+func translateSessionPersistence(sp *gatewayv1.SessionPersistence, defaultName string, match gatewayv1.HTTPRouteMatch) (*anypb.Any, error) {
+	name := defaultName
+	if sp.SessionName != nil && *sp.SessionName != "" {
+		name = *sp.SessionName
+	}
+
+	spType := gatewayv1.CookieBasedSessionPersistence
+	if sp.Type != nil {
+		spType = *sp.Type
+	}
+
+	var sessionState *corev3.TypedExtensionConfig
+	switch spType {
+	case gatewayv1.CookieBasedSessionPersistence:
+		cookie := &httpv3.Cookie{
+			Name: name,
+			Path: "/",
+			// A zero TTL produces a session cookie that expires with the client's session.
+			Ttl: durationpb.New(0),
+		}
+		if match.Path != nil && match.Path.Value != nil && (match.Path.Type == nil || *match.Path.Type != gatewayv1.PathMatchRegularExpression) {
+			cookie.Path = *match.Path.Value
+		}
+		if sp.AbsoluteTimeout != nil && cookieLifetimeType(sp) == gatewayv1.PermanentCookieLifetimeType {
+			// Envoy sets the TTL as the Max-Age of the cookie and embeds the expiry in its
+			// value, which it stops honoring once the TTL has elapsed.
+			timeout, err := time.ParseDuration(string(*sp.AbsoluteTimeout))
+			if err != nil {
+				return nil, fmt.Errorf("invalid sessionPersistence.absoluteTimeout %q: %w", *sp.AbsoluteTimeout, err)
+			}
+			cookie.Ttl = durationpb.New(timeout)
+		}
+		sessionState = &corev3.TypedExtensionConfig{
+			Name:        constants.CookieSessionStateName,
+			TypedConfig: protoconv.MessageToAny(&cookiesessionv3.CookieBasedSessionState{Cookie: cookie}),
+		}
+	case gatewayv1.HeaderBasedSessionPersistence:
+		sessionState = &corev3.TypedExtensionConfig{
+			Name:        constants.HeaderSessionStateName,
+			TypedConfig: protoconv.MessageToAny(&headersessionv3.HeaderBasedSessionState{Name: name}),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported session persistence type: %s", spType)
+	}
+
+	return protoconv.MessageToAnyWithError(&statefulsessionv3.StatefulSessionPerRoute{
+		Override: &statefulsessionv3.StatefulSessionPerRoute_StatefulSession{
+			StatefulSession: &statefulsessionv3.StatefulSession{
+				SessionState: sessionState,
+			},
+		},
+	})
+}
+
 func translateRequestHeaderModifierFilter(headerModifier *gatewayv1.HTTPHeaderFilter) ([]*corev3.HeaderValueOption, []string) {
 	if headerModifier == nil {
 		return nil, nil
@@ -401,89 +626,41 @@ func createFailureCondition(reason gatewayv1.RouteConditionReason, message strin
 	}
 }
 
-// buildHTTPRouteAction returns an action, a list of valid BackendRefs, and a structured error.
+// buildHTTPRouteAction returns an action, a list of valid BackendRefs, and a structured error for
+// each backendRef that does not resolve and for each backendRef whose filters cannot be applied.
+// Such backendRefs do not invalidate the whole action: their share of the traffic is sent to a
+// cluster that does not exist, for which Envoy answers with a 500, while the valid backends keep
+// serving theirs. The action is nil only if no backendRef carries any weight. The match and
+// whether the rule rewrites the path are needed to apply the path rewrites of backendRefs.
 func buildHTTPRouteAction(
 	namespace string,
 	backendRefs []gatewayv1.HTTPBackendRef,
+	match gatewayv1.HTTPRouteMatch,
+	ruleRewritesPath bool,
 	serviceLister corev1listers.ServiceLister,
 	backendLister aigatewaylisters.XBackendDestinationLister,
-) (*routev3.RouteAction, []RouteBackend, error) {
+	watchedNamespaces sets.Set[string],
+) (*routev3.RouteAction, []RouteBackend, []*ControllerError, []*ControllerError) {
 	weightedClusters := &routev3.WeightedCluster{}
 	var validBackends []RouteBackend
+	var invalidRefErrs []*ControllerError
+	var filterErrs []*ControllerError
 
 	for _, httpBackendRef := range backendRefs {
-		backend, err := fetchBackend(namespace, httpBackendRef.BackendRef, backendLister, serviceLister)
-		if err != nil {
-			return nil, nil, err
-		} else if backend == nil {
-			return nil, nil, &ControllerError{
-				Reason:  string(gatewayv1.RouteReasonBackendNotFound),
-				Message: fmt.Sprintf("Backend %s/%s could not be resolved", namespace, httpBackendRef.BackendRef.Name),
-			}
-		}
-		validBackends = append(validBackends, *backend)
 		weight := int32(1)
 		if httpBackendRef.Weight != nil {
 			weight = *httpBackendRef.Weight
 		}
-		if weight == 0 {
-			continue
-		}
 
-		// Generate the cluster name, accounting for port
-		var port = httpBackendRef.BackendRef.Port
-		switch backend.Source.Kind {
-		case "Backend":
-			if port == nil {
-				return nil, nil, &ControllerError{
-					Reason:  string(gatewayv1.RouteReasonResolvedRefs),
-					Message: fmt.Sprintf("Port must be specified for Backend backend %s/%s", backend.Source.Namespace, backend.Source.Name),
-				}
-			}
-			// For a Backend backendRef, the port is also required, but it corresponds to the targetPort instead
-			found := false
-			for _, p := range backend.Ports {
-				if p.Number == uint32(*port) {
-					targetPort := gatewayv1.PortNumber(p.Number)
-					port = &targetPort
-					found = true
-					break
-				}
-			}
-			if !found {
-				return nil, nil, &ControllerError{
-					Reason:  string(gatewayv1.RouteReasonResolvedRefs),
-					Message: fmt.Sprintf("No targetPort %d found for Backend backend %s/%s", *port, backend.Source.Namespace, backend.Source.Name),
-				}
-			}
-		case "Service":
-			// For services, port is required; if not specified, return an error
-			if port == nil {
-				return nil, nil, &ControllerError{
-					Reason:  string(gatewayv1.RouteReasonResolvedRefs),
-					Message: fmt.Sprintf("Port must be specified for Service backend %s/%s", backend.Source.Namespace, backend.Source.Name),
-				}
-			}
-			// Since we know there is a port, find the corresponding targetPort (since that what Envoy will route to)
-			found := false
-			for _, p := range backend.Ports {
-				if p.FrontendPort == uint32(*port) {
-					targetPort := gatewayv1.PortNumber(p.Number) // Switch to the targetPort
-					port = &targetPort
-					found = true
-					break
-				}
-			}
-			if !found {
-				return nil, nil, &ControllerError{
-					Reason:  string(gatewayv1.RouteReasonResolvedRefs),
-					Message: fmt.Sprintf("No targetPort found for port %d for Service backend %s/%s", *port, backend.Source.Namespace, backend.Source.Name),
-				}
-			}
+		backend, clusterName, err := resolveBackendRefCluster(namespace, httpBackendRef.BackendRef, serviceLister, backendLister, watchedNamespaces)
+		if err != nil {
+			invalidRefErrs = append(invalidRefErrs, err)
+			addInvalidBackendShare(weightedClusters, weight)
+			continue
 		}
 
 		clusterWeight := &routev3.WeightedCluster_ClusterWeight{
-			Name:   fmt.Sprintf(constants.ClusterNameFormat, backend.Source.Namespace, backend.ClusterName(), *port),
+			Name:   clusterName,
 			Weight: &wrapperspb.UInt32Value{Value: uint32(weight)},
 		}
 
@@ -494,31 +671,232 @@ func buildHTTPRouteAction(
 			}
 		}
 
+		// Apply filters scoped to this backendRef. These only take effect when this
+		// cluster is the one selected from the weighted set. A backendRef whose filters
+		// can't be applied gets no traffic, like one that doesn't resolve.
+		if err := translateBackendRefFilters(clusterWeight, httpBackendRef.Filters, match, ruleRewritesPath); err != nil {
+			filterErrs = append(filterErrs, &ControllerError{
+				Reason:  string(gatewayv1.RouteReasonIncompatibleFilters),
+				Message: fmt.Sprintf("Filters of backendRef %s/%s cannot be applied: %v", namespace, httpBackendRef.Name, err),
+			})
+			addInvalidBackendShare(weightedClusters, weight)
+			continue
+		}
+
+		validBackends = append(validBackends, *backend)
+		if weight == 0 {
+			continue
+		}
 		weightedClusters.Clusters = append(weightedClusters.Clusters, clusterWeight)
 	}
 
 	if len(weightedClusters.Clusters) == 0 {
-		return nil, nil, &ControllerError{
-			Reason:  string(gatewayv1.RouteReasonUnsupportedValue),
-			Message: "no valid backends provided with a weight > 0",
-		}
+		// Requests get a 500 when no backendRef carries weight, which is not an error by itself.
+		return nil, validBackends, invalidRefErrs, filterErrs
 	}
 
 	action := &routev3.RouteAction{
 		ClusterSpecifier: &routev3.RouteAction_WeightedClusters{
 			WeightedClusters: weightedClusters,
 		},
+		// Requests that land on the share of an invalid backendRef must get a 500 per the Gateway API spec.
+		ClusterNotFoundResponseCode: routev3.RouteAction_INTERNAL_SERVER_ERROR,
+	}
+	return action, validBackends, invalidRefErrs, filterErrs
+}
+
+// addInvalidBackendShare sends the share of traffic of an invalid backendRef to a cluster that
+// does not exist, so that Envoy answers those requests with a 500.
+func addInvalidBackendShare(weightedClusters *routev3.WeightedCluster, weight int32) {
+	if weight == 0 {
+		return
+	}
+	weightedClusters.Clusters = append(weightedClusters.Clusters, &routev3.WeightedCluster_ClusterWeight{
+		Name:   constants.InvalidBackendClusterName,
+		Weight: &wrapperspb.UInt32Value{Value: uint32(weight)},
+	})
+}
+
+// resolveBackendRefCluster resolves a BackendRef to its backend and the name of the Envoy cluster
+// traffic for it should be sent to.
+func resolveBackendRefCluster(
+	namespace string,
+	backendRef gatewayv1.BackendRef,
+	serviceLister corev1listers.ServiceLister,
+	backendLister aigatewaylisters.XBackendDestinationLister,
+	watchedNamespaces sets.Set[string],
+) (*RouteBackend, string, *ControllerError) {
+	backend, err := fetchBackend(namespace, backendRef, backendLister, serviceLister, watchedNamespaces)
+	if err != nil {
+		var controllerErr *ControllerError
+		if errors.As(err, &controllerErr) {
+			return nil, "", controllerErr
+		}
+		return nil, "", &ControllerError{
+			Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+			Message: fmt.Sprintf("Backend %s/%s could not be resolved: %v", namespace, backendRef.Name, err),
+		}
+	} else if backend == nil {
+		return nil, "", &ControllerError{
+			Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+			Message: fmt.Sprintf("Backend %s/%s could not be resolved", namespace, backendRef.Name),
+		}
 	}
 
-	return action, validBackends, nil
+	// Generate the cluster name, accounting for port
+	var port = backendRef.Port
+	switch backend.Source.Kind {
+	case "Backend":
+		if port == nil {
+			return nil, "", &ControllerError{
+				Reason:  string(gatewayv1.RouteReasonUnsupportedValue),
+				Message: fmt.Sprintf("Port must be specified for Backend backend %s/%s", backend.Source.Namespace, backend.Source.Name),
+			}
+		}
+		// For a Backend backendRef, the port is also required, but it corresponds to the targetPort instead
+		found := false
+		for _, p := range backend.Ports {
+			if p.Number == uint32(*port) {
+				targetPort := gatewayv1.PortNumber(p.Number)
+				port = &targetPort
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, "", &ControllerError{
+				Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+				Message: fmt.Sprintf("No targetPort %d found for Backend backend %s/%s", *port, backend.Source.Namespace, backend.Source.Name),
+			}
+		}
+	case "Service":
+		// For services, port is required; if not specified, return an error
+		if port == nil {
+			return nil, "", &ControllerError{
+				Reason:  string(gatewayv1.RouteReasonUnsupportedValue),
+				Message: fmt.Sprintf("Port must be specified for Service backend %s/%s", backend.Source.Namespace, backend.Source.Name),
+			}
+		}
+		// Clusters for services are keyed by the Service port; the endpoint port is resolved
+		// from the EndpointSlices.
+		found := false
+		for _, p := range backend.Ports {
+			if p.Number == uint32(*port) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, "", &ControllerError{
+				Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+				Message: fmt.Sprintf("No port %d found for Service backend %s/%s", *port, backend.Source.Namespace, backend.Source.Name),
+			}
+		}
+	}
+
+	return backend, fmt.Sprintf(constants.ClusterNameFormat, backend.Source.Namespace, backend.ClusterName(), *port), nil
+}
+
+// resolvedRefsReasonPrecedence orders the reasons of a False ResolvedRefs condition the way the
+// Gateway API lists them, from the most to the least important to report.
+var resolvedRefsReasonPrecedence = []gatewayv1.RouteConditionReason{
+	gatewayv1.RouteReasonRefNotPermitted,
+	gatewayv1.RouteReasonInvalidKind,
+	gatewayv1.RouteReasonBackendNotFound,
+	gatewayv1.RouteReasonUnsupportedProtocol,
+}
+
+// joinControllerErrors folds the errors of several backendRefs or rules into one. Its reason is
+// the one of highest precedence among the errors, and its message lists every distinct error with
+// its own reason.
+func joinControllerErrors(errs []*ControllerError) *ControllerError {
+	if len(errs) == 0 {
+		return nil
+	}
+	reason := errs[0].Reason
+	rank := reasonRank(reason)
+	var messages []string
+	seen := sets.New[string]()
+	for _, err := range errs {
+		if r := reasonRank(err.Reason); r < rank {
+			reason, rank = err.Reason, r
+		}
+		message := fmt.Sprintf("%s (%s)", err.Message, err.Reason)
+		if seen.Has(message) {
+			continue
+		}
+		seen.Insert(message)
+		messages = append(messages, message)
+	}
+	if len(messages) == 1 {
+		return &ControllerError{Reason: reason, Message: errs[0].Message}
+	}
+	return &ControllerError{
+		Reason:  reason,
+		Message: strings.Join(messages, "; "),
+	}
+}
+
+// reasonRank returns the position of a reason in resolvedRefsReasonPrecedence, reasons missing
+// from it coming last.
+func reasonRank(reason string) int {
+	for i, r := range resolvedRefsReasonPrecedence {
+		if string(r) == reason {
+			return i
+		}
+	}
+	return len(resolvedRefsReasonPrecedence)
+}
+
+// translateBackendRefFilters applies the filters declared on an HTTPBackendRef to the
+// corresponding weighted cluster entry. Path rewrites are applied by the backend path rewrite
+// filter, and can't be combined with a path rewrite of the rule.
+func translateBackendRefFilters(clusterWeight *routev3.WeightedCluster_ClusterWeight, filters []gatewayv1.HTTPRouteFilter, match gatewayv1.HTTPRouteMatch, ruleRewritesPath bool) error {
+	for _, filter := range filters {
+		switch filter.Type {
+		case gatewayv1.HTTPRouteFilterRequestHeaderModifier:
+			adds, removes := translateRequestHeaderModifierFilter(filter.RequestHeaderModifier)
+			clusterWeight.RequestHeadersToAdd = append(clusterWeight.RequestHeadersToAdd, adds...)
+			clusterWeight.RequestHeadersToRemove = append(clusterWeight.RequestHeadersToRemove, removes...)
+		case gatewayv1.HTTPRouteFilterResponseHeaderModifier:
+			adds, removes := translateResponseHeaderModifierFilter(filter.ResponseHeaderModifier)
+			clusterWeight.ResponseHeadersToAdd = append(clusterWeight.ResponseHeadersToAdd, adds...)
+			clusterWeight.ResponseHeadersToRemove = append(clusterWeight.ResponseHeadersToRemove, removes...)
+		case gatewayv1.HTTPRouteFilterURLRewrite:
+			if filter.URLRewrite == nil {
+				continue
+			}
+			if filter.URLRewrite.Path != nil {
+				if ruleRewritesPath {
+					return fmt.Errorf("URLRewrite path modifiers cannot be set on both the rule and its backendRefs")
+				}
+				if err := translateBackendRefPathRewrite(clusterWeight, filter.URLRewrite.Path, match); err != nil {
+					return err
+				}
+			}
+			if filter.URLRewrite.Hostname != nil {
+				// An explicit hostname rewrite takes precedence over the FQDN backend's hostname.
+				clusterWeight.HostRewriteSpecifier = &routev3.WeightedCluster_ClusterWeight_HostRewriteLiteral{
+					HostRewriteLiteral: string(*filter.URLRewrite.Hostname),
+				}
+			}
+		case gatewayv1.HTTPRouteFilterExtensionRef:
+			klog.Infof("ExtensionRef filter not implemented: %v", filter.ExtensionRef)
+		default:
+			klog.Warningf("Unsupported HTTPBackendRef filter type: %s", filter.Type)
+		}
+	}
+	return nil
 }
 
-// fetchBackend retrieves a Backend resource based on the BackendRef
+// fetchBackend retrieves a Backend resource based on the BackendRef. Objects in namespaces outside
+// watchedNamespaces cannot be looked up, and are reported as such rather than as missing.
 func fetchBackend(
 	namespace string,
 	backendRef gatewayv1.BackendRef,
 	backendLister aigatewaylisters.XBackendDestinationLister,
 	serviceLister corev1listers.ServiceLister,
+	watchedNamespaces sets.Set[string],
 ) (*RouteBackend, error) {
 	// Determine the namespace for the backend
 	backendNamespace := namespace
@@ -526,8 +904,15 @@ func fetchBackend(
 		backendNamespace = string(*backendRef.Namespace)
 	}
 
-	// Handle different backend kinds
-	switch *backendRef.Kind {
+	// Handle different backend kinds, defaulting to Service as the Gateway API does
+	kind := "Service"
+	if backendRef.Kind != nil {
+		kind = string(*backendRef.Kind)
+	}
+	if !namespaceWatched(watchedNamespaces, backendNamespace) {
+		return nil, unwatchedNamespaceError(kind, backendNamespace, string(backendRef.Name))
+	}
+	switch kind {
 	case "Backend":
 		// Fetch the Backend resource
 		backend, err := backendLister.XBackendDestinations(backendNamespace).Get(string(backendRef.Name))
@@ -543,19 +928,52 @@ func fetchBackend(
 		var ports []RouteBackendPort
 		for _, port := range backend.Spec.Destination.Ports {
 			ports = append(ports, RouteBackendPort{
-				Number:   port.Number,
-				Protocol: port.Protocol,
-				TLS:      port.TLS,
+				Number:     port.Number,
+				TargetPort: port.Number,
+				Protocol:   port.Protocol,
+				TLS:        port.TLS,
 				// TODO: Handle protocol options
 			})
 		}
 		var hostname string
+		var service types.NamespacedName
 		switch backend.Spec.Destination.Type {
 		case v0alpha0.BackendTypeFqdn:
 			hostname = backend.Spec.Destination.FQDN.Hostname
 		case v0alpha0.BackendTypeService:
-			svc := backend.Spec.Destination.Service
-			hostname = fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, backendNamespace)
+			if backend.Spec.Destination.Service == nil {
+				return nil, &ControllerError{
+					Reason:  string(gatewayv1.RouteReasonUnsupportedValue),
+					Message: fmt.Sprintf("Backend %s/%s has type Service but no service configuration", backendNamespace, backendRef.Name),
+				}
+			}
+			service = serviceBackendKey(backend.Spec.Destination.Service, backendNamespace)
+			if !namespaceWatched(watchedNamespaces, service.Namespace) {
+				return nil, unwatchedNamespaceError("Service", service.Namespace, service.Name)
+			}
+			svc, err := serviceLister.Services(service.Namespace).Get(service.Name)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					return nil, &ControllerError{
+						Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+						Message: fmt.Sprintf("Service %s referenced by Backend %s/%s not found", service, backendNamespace, backendRef.Name),
+					}
+				}
+				return nil, err
+			}
+			// The declared ports are Service ports; resolve each to the port its endpoints listen on.
+			for i := range ports {
+				servicePort, ok := findServicePort(svc, ports[i].Number)
+				if !ok {
+					return nil, &ControllerError{
+						Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+						Message: fmt.Sprintf("Service %s referenced by Backend %s/%s has no port %d", service, backendNamespace, backendRef.Name, ports[i].Number),
+					}
+				}
+				ports[i].Name = servicePort.Name
+				ports[i].TargetPort = serviceTargetPort(servicePort)
+			}
+			hostname = fmt.Sprintf("%s.%s.svc.cluster.local", service.Name, service.Namespace)
 		default:
 			return nil, &ControllerError{
 				Reason:  string(gatewayv1.RouteReasonUnsupportedValue),
@@ -577,6 +995,7 @@ func fetchBackend(
 			},
 			Hostname:       hostname,
 			Ports:          ports,
+			Service:        service,
 			ResolutionType: resolutionType,
 		}, nil
 
@@ -593,12 +1012,6 @@ func fetchBackend(
 			return nil, err
 		}
 		hostname := fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, backendNamespace)
-		nameToPort := map[string]v1.ServicePort{}
-		for _, port := range svc.Spec.Ports {
-			if port.Name != "" {
-				nameToPort[port.Name] = port
-			}
-		}
 		var ports []RouteBackendPort
 		for _, port := range svc.Spec.Ports {
 			// TODO: Let's not support UDP for now
@@ -615,18 +1028,12 @@ func fetchBackend(
 					klog.Warningf("Unsupported AppProtocol %s for Service %s/%s port %d, defaulting to TCP", *port.AppProtocol, backendNamespace, svc.Name, port.Port)
 				}
 			}
-			number := uint32(port.Port)
-			if port.TargetPort.Type == intstr.Int && port.TargetPort.IntValue() > 0 {
-				number = uint32(port.TargetPort.IntValue())
-			} else if port.TargetPort.Type == intstr.String {
-				klog.Warningf("Named TargetPort %s for Service %s/%s port %d not supported, skipping...", port.TargetPort.StrVal, backendNamespace, svc.Name, port.Port)
-				continue
-			}
 			port := RouteBackendPort{
-				Number:       number,
-				FrontendPort: uint32(port.Port),
-				Protocol:     protocol,
-				TLS:          nil, // TODO: Lookup relevant BackendTLSPolicies
+				Number:     uint32(port.Port),
+				Name:       port.Name,
+				TargetPort: serviceTargetPort(port),
+				Protocol:   protocol,
+				TLS:        nil, // TODO: Lookup relevant BackendTLSPolicies
 			}
 			ports = append(ports, port)
 		}
@@ -638,19 +1045,68 @@ func fetchBackend(
 			},
 			Hostname:       hostname,
 			Ports:          ports,
+			Service:        types.NamespacedName{Namespace: backendNamespace, Name: svc.Name},
 			ResolutionType: RouteBackendResolutionTypeEDS,
 		}, nil
 
 	default:
 		return nil, &ControllerError{
-			Reason:  string(gatewayv1.RouteReasonUnsupportedValue),
-			Message: fmt.Sprintf("unsupported backend kind: %s", *backendRef.Kind),
+			Reason:  string(gatewayv1.RouteReasonInvalidKind),
+			Message: fmt.Sprintf("unsupported backend kind: %s", kind),
 		}
 	}
 }
 
-// translateHTTPRouteMatch translates a Gateway API HTTPRouteMatch into an Envoy RouteMatch.
-func translateHTTPRouteMatch(match gatewayv1.HTTPRouteMatch, generation int64) (*routev3.RouteMatch, metav1.Condition) {
+// namespaceWatched returns whether the listers see objects in the namespace. A nil set means
+// that every namespace is watched.
+func namespaceWatched(watchedNamespaces sets.Set[string], namespace string) bool {
+	return watchedNamespaces == nil || watchedNamespaces.Has(namespace)
+}
+
+// unwatchedNamespaceError reports a backendRef to an object the controller cannot see because
+// its namespace is not watched.
+func unwatchedNamespaceError(kind, namespace, name string) *ControllerError {
+	return &ControllerError{
+		Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+		Message: fmt.Sprintf("%s %s/%s cannot be resolved: namespace %s is not watched by the controller", kind, namespace, name, namespace),
+	}
+}
+
+// serviceBackendKey returns the Service a Service-typed Backend points at, defaulting to the
+// Backend's namespace.
+func serviceBackendKey(serviceBackend *v0alpha0.ServiceBackend, backendNamespace string) types.NamespacedName {
+	namespace := serviceBackend.Namespace
+	if namespace == "" {
+		namespace = backendNamespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: serviceBackend.Name}
+}
+
+func findServicePort(svc *corev1.Service, port uint32) (corev1.ServicePort, bool) {
+	for _, servicePort := range svc.Spec.Ports {
+		if uint32(servicePort.Port) == port {
+			return servicePort, true
+		}
+	}
+	return corev1.ServicePort{}, false
+}
+
+// serviceTargetPort returns the numeric targetPort of a Service port. A named targetPort can only
+// be resolved per endpoint, from the EndpointSlices, so 0 is returned for it.
+func serviceTargetPort(port corev1.ServicePort) uint32 {
+	switch {
+	case port.TargetPort.Type == intstr.String:
+		return 0
+	case port.TargetPort.IntValue() > 0:
+		return uint32(port.TargetPort.IntValue())
+	default:
+		return uint32(port.Port)
+	}
+}
+
+// translateHTTPRouteMatch translates a Gateway API HTTPRouteMatch into an Envoy RouteMatch, or
+// returns an error if the match cannot be expressed in Envoy.
+func translateHTTPRouteMatch(match gatewayv1.HTTPRouteMatch) (*routev3.RouteMatch, error) {
 	routeMatch := &routev3.RouteMatch{}
 
 	if match.Path != nil {
@@ -659,8 +1115,7 @@ func translateHTTPRouteMatch(match gatewayv1.HTTPRouteMatch, generation int64) (
 			pathType = *match.Path.Type
 		}
 		if match.Path.Value == nil {
-			msg := "path match value cannot be nil"
-			return nil, createFailureCondition(gatewayv1.RouteReasonUnsupportedValue, msg, generation)
+			return nil, errors.New("path match value cannot be nil")
 		}
 		pathValue := *match.Path.Value
 
@@ -682,14 +1137,28 @@ func translateHTTPRouteMatch(match gatewayv1.HTTPRouteMatch, generation int64) (
 				},
 			}
 		default:
-			msg := fmt.Sprintf("unsupported path match type: %s", pathType)
-			return nil, createFailureCondition(gatewayv1.RouteReasonUnsupportedValue, msg, generation)
+			return nil, fmt.Errorf("unsupported path match type: %s", pathType)
 		}
 	} else {
 		// As per Gateway API spec, a nil path match defaults to matching everything.
 		routeMatch.PathSpecifier = &routev3.RouteMatch_Prefix{Prefix: "/"}
 	}
 
+	// Translate Method Match into a match on the :method pseudo-header
+	if match.Method != nil {
+		if !supportedHTTPMethods.Has(*match.Method) {
+			return nil, fmt.Errorf("unsupported method match: %s", *match.Method)
+		}
+		routeMatch.Headers = append(routeMatch.Headers, &routev3.HeaderMatcher{
+			Name: methodHeaderName,
+			HeaderMatchSpecifier: &routev3.HeaderMatcher_StringMatch{
+				StringMatch: &matcherv3.StringMatcher{
+					MatchPattern: &matcherv3.StringMatcher_Exact{Exact: string(*match.Method)},
+				},
+			},
+		})
+	}
+
 	// Translate Header Matches
 	for _, headerMatch := range match.Headers {
 		headerMatcher := &routev3.HeaderMatcher{
@@ -715,8 +1184,7 @@ func translateHTTPRouteMatch(match gatewayv1.HTTPRouteMatch, generation int64) (
 				},
 			}
 		default:
-			msg := fmt.Sprintf("unsupported header match type: %s", matchType)
-			return nil, createFailureCondition(gatewayv1.RouteReasonUnsupportedValue, msg, generation)
+			return nil, fmt.Errorf("unsupported header match type: %s", matchType)
 		}
 		routeMatch.Headers = append(routeMatch.Headers, headerMatcher)
 	}
@@ -735,8 +1203,7 @@ func translateHTTPRouteMatch(match gatewayv1.HTTPRouteMatch, generation int64) (
 		routeMatch.QueryParameters = append(routeMatch.QueryParameters, queryMatcher)
 	}
 
-	// If all translations were successful, return the final object and a success condition.
-	return routeMatch, createSuccessCondition(generation)
+	return routeMatch, nil
 }
 
 // sortRoutes is the definitive sorter for Envoy routes based on Gateway API precedence.
@@ -770,14 +1237,25 @@ func sortRoutes(routes []*routev3.Route) {
 			return len(prefixI) > len(prefixJ) // Longer prefix is higher precedence
 		}
 
-		// Precedence Rule 3: Number of Header Matches
+		// Precedence Rule 3: Method Match
+		hasMethodI := hasMethodMatch(matchI)
+		hasMethodJ := hasMethodMatch(matchJ)
+		if hasMethodI != hasMethodJ {
+			return hasMethodI // A method match is higher precedence
+		}
+
+		// Precedence Rule 4: Number of Header Matches (excluding the method match)
 		headerCountI := len(matchI.GetHeaders())
 		headerCountJ := len(matchJ.GetHeaders())
+		if hasMethodI {
+			headerCountI--
+			headerCountJ--
+		}
 		if headerCountI != headerCountJ {
 			return headerCountI > headerCountJ // More headers is higher precedence
 		}
 
-		// Precedence Rule 4: Number of Query Param Matches
+		// Precedence Rule 5: Number of Query Param Matches
 		queryCountI := len(matchI.GetQueryParameters())
 		queryCountJ := len(matchJ.GetQueryParameters())
 		if queryCountI != queryCountJ {
@@ -789,6 +1267,16 @@ func sortRoutes(routes []*routev3.Route) {
 	})
 }
 
+// hasMethodMatch reports whether a route match constrains the HTTP method.
+func hasMethodMatch(match *routev3.RouteMatch) bool {
+	for _, header := range match.GetHeaders() {
+		if header.GetName() == methodHeaderName {
+			return true
+		}
+	}
+	return false
+}
+
 // getPathMatchValue is a helper to extract the path string for comparison.
 func getPathMatchValue(match *routev3.RouteMatch) string {
 	if match.GetPath() != "" {
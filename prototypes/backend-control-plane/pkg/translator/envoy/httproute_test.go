@@ -0,0 +1,389 @@
+package envoy
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"google.golang.org/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/api/v0alpha0"
+	aigatewaylisters "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/k8s/client/listers/api/v0alpha0"
+)
+
+func TestValidateSessionPersistence(t *testing.T) {
+	permanent := &gatewayv1.CookieConfig{LifetimeType: ptr.To(gatewayv1.PermanentCookieLifetimeType)}
+	session := &gatewayv1.CookieConfig{LifetimeType: ptr.To(gatewayv1.SessionCookieLifetimeType)}
+
+	tests := []struct {
+		name    string
+		sp      gatewayv1.SessionPersistence
+		wantErr bool
+	}{
+		{
+			name: "cookie without timeouts",
+			sp:   gatewayv1.SessionPersistence{},
+		},
+		{
+			name: "header without timeouts",
+			sp:   gatewayv1.SessionPersistence{Type: ptr.To(gatewayv1.HeaderBasedSessionPersistence)},
+		},
+		{
+			name: "permanent cookie with absolute timeout",
+			sp:   gatewayv1.SessionPersistence{AbsoluteTimeout: ptr.To(gatewayv1.Duration("1h")), CookieConfig: permanent},
+		},
+		{
+			name:    "idle timeout",
+			sp:      gatewayv1.SessionPersistence{IdleTimeout: ptr.To(gatewayv1.Duration("10m")), CookieConfig: permanent, AbsoluteTimeout: ptr.To(gatewayv1.Duration("1h"))},
+			wantErr: true,
+		},
+		{
+			name:    "session cookie with absolute timeout",
+			sp:      gatewayv1.SessionPersistence{AbsoluteTimeout: ptr.To(gatewayv1.Duration("1h")), CookieConfig: session},
+			wantErr: true,
+		},
+		{
+			name:    "absolute timeout defaults to a session cookie",
+			sp:      gatewayv1.SessionPersistence{AbsoluteTimeout: ptr.To(gatewayv1.Duration("1h"))},
+			wantErr: true,
+		},
+		{
+			name:    "header with absolute timeout",
+			sp:      gatewayv1.SessionPersistence{Type: ptr.To(gatewayv1.HeaderBasedSessionPersistence), AbsoluteTimeout: ptr.To(gatewayv1.Duration("1h"))},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSessionPersistence(&tt.sp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSessionPersistence() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJoinControllerErrors(t *testing.T) {
+	notFound := &ControllerError{Reason: string(gatewayv1.RouteReasonBackendNotFound), Message: "Backend default/missing not found"}
+	notPermitted := &ControllerError{Reason: string(gatewayv1.RouteReasonRefNotPermitted), Message: "Backend other/llm is not permitted"}
+	invalidKind := &ControllerError{Reason: string(gatewayv1.RouteReasonInvalidKind), Message: "Kind ConfigMap is not supported"}
+
+	tests := []struct {
+		name string
+		errs []*ControllerError
+		want *ControllerError
+	}{
+		{
+			name: "no errors",
+		},
+		{
+			name: "single error keeps its message",
+			errs: []*ControllerError{notFound},
+			want: notFound,
+		},
+		{
+			name: "repeated errors are reported once",
+			errs: []*ControllerError{notFound, notFound},
+			want: notFound,
+		},
+		{
+			name: "reason is chosen by precedence, not position",
+			errs: []*ControllerError{notFound, notPermitted},
+			want: &ControllerError{
+				Reason:  string(gatewayv1.RouteReasonRefNotPermitted),
+				Message: "Backend default/missing not found (BackendNotFound); Backend other/llm is not permitted (RefNotPermitted)",
+			},
+		},
+		{
+			name: "unknown reasons come last",
+			errs: []*ControllerError{{Reason: "Other", Message: "other"}, invalidKind},
+			want: &ControllerError{
+				Reason:  string(gatewayv1.RouteReasonInvalidKind),
+				Message: "other (Other); Kind ConfigMap is not supported (InvalidKind)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := joinControllerErrors(tt.errs)
+			if !equality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("joinControllerErrors() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslateHTTPRouteMatch(t *testing.T) {
+	exact := func(value string) *matcherv3.StringMatcher {
+		return &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_Exact{Exact: value}}
+	}
+	re2 := func(regex string) *matcherv3.RegexMatcher {
+		return &matcherv3.RegexMatcher{
+			EngineType: &matcherv3.RegexMatcher_GoogleRe2{GoogleRe2: &matcherv3.RegexMatcher_GoogleRE2{}},
+			Regex:      regex,
+		}
+	}
+	path := func(pathType gatewayv1.PathMatchType, value string) *gatewayv1.HTTPPathMatch {
+		return &gatewayv1.HTTPPathMatch{Type: ptr.To(pathType), Value: ptr.To(value)}
+	}
+
+	tests := []struct {
+		name    string
+		match   gatewayv1.HTTPRouteMatch
+		want    *routev3.RouteMatch
+		wantErr bool
+	}{
+		{
+			name:  "empty match",
+			match: gatewayv1.HTTPRouteMatch{},
+			want:  &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/"}},
+		},
+		{
+			name:  "exact path",
+			match: gatewayv1.HTTPRouteMatch{Path: path(gatewayv1.PathMatchExact, "/v1/models")},
+			want:  &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Path{Path: "/v1/models"}},
+		},
+		{
+			name:  "root prefix",
+			match: gatewayv1.HTTPRouteMatch{Path: path(gatewayv1.PathMatchPathPrefix, "/")},
+			want:  &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/"}},
+		},
+		{
+			name:  "prefix matches by segment",
+			match: gatewayv1.HTTPRouteMatch{Path: path(gatewayv1.PathMatchPathPrefix, "/v1/")},
+			want:  &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_PathSeparatedPrefix{PathSeparatedPrefix: "/v1"}},
+		},
+		{
+			name:  "regular expression path",
+			match: gatewayv1.HTTPRouteMatch{Path: path(gatewayv1.PathMatchRegularExpression, "/v[0-9]+/.*")},
+			want:  &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_SafeRegex{SafeRegex: re2("/v[0-9]+/.*")}},
+		},
+		{
+			name:  "method",
+			match: gatewayv1.HTTPRouteMatch{Method: ptr.To(gatewayv1.HTTPMethodPost)},
+			want: &routev3.RouteMatch{
+				PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/"},
+				Headers: []*routev3.HeaderMatcher{{
+					Name:                 ":method",
+					HeaderMatchSpecifier: &routev3.HeaderMatcher_StringMatch{StringMatch: exact("POST")},
+				}},
+			},
+		},
+		{
+			name: "method, headers and query parameters",
+			match: gatewayv1.HTTPRouteMatch{
+				Method: ptr.To(gatewayv1.HTTPMethodGet),
+				Headers: []gatewayv1.HTTPHeaderMatch{
+					{Name: "x-model", Value: "gpt"},
+					{Type: ptr.To(gatewayv1.HeaderMatchRegularExpression), Name: "x-tenant", Value: "team-.*"},
+				},
+				QueryParams: []gatewayv1.HTTPQueryParamMatch{{Name: "stream", Value: "true"}},
+			},
+			want: &routev3.RouteMatch{
+				PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/"},
+				Headers: []*routev3.HeaderMatcher{
+					{Name: ":method", HeaderMatchSpecifier: &routev3.HeaderMatcher_StringMatch{StringMatch: exact("GET")}},
+					{Name: "x-model", HeaderMatchSpecifier: &routev3.HeaderMatcher_StringMatch{StringMatch: exact("gpt")}},
+					{Name: "x-tenant", HeaderMatchSpecifier: &routev3.HeaderMatcher_SafeRegexMatch{SafeRegexMatch: re2("team-.*")}},
+				},
+				QueryParameters: []*routev3.QueryParameterMatcher{{
+					Name:                         "stream",
+					QueryParameterMatchSpecifier: &routev3.QueryParameterMatcher_StringMatch{StringMatch: exact("true")},
+				}},
+			},
+		},
+		{
+			name:    "CONNECT cannot be matched by method",
+			match:   gatewayv1.HTTPRouteMatch{Method: ptr.To(gatewayv1.HTTPMethodConnect)},
+			wantErr: true,
+		},
+		{
+			name:    "path without a value",
+			match:   gatewayv1.HTTPRouteMatch{Path: &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchExact)}},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported header match type",
+			match:   gatewayv1.HTTPRouteMatch{Headers: []gatewayv1.HTTPHeaderMatch{{Type: ptr.To(gatewayv1.HeaderMatchType("Prefix")), Name: "x-model", Value: "gpt"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := translateHTTPRouteMatch(tt.match)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("translateHTTPRouteMatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("translateHTTPRouteMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortRoutes(t *testing.T) {
+	route := func(name string, match gatewayv1.HTTPRouteMatch) *routev3.Route {
+		routeMatch, err := translateHTTPRouteMatch(match)
+		if err != nil {
+			t.Fatalf("translateHTTPRouteMatch() error = %v", err)
+		}
+		return &routev3.Route{Name: name, Match: routeMatch}
+	}
+	prefix := func(value string) *gatewayv1.HTTPPathMatch {
+		return &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchPathPrefix), Value: ptr.To(value)}
+	}
+	headers := func(names ...string) []gatewayv1.HTTPHeaderMatch {
+		var matches []gatewayv1.HTTPHeaderMatch
+		for _, name := range names {
+			matches = append(matches, gatewayv1.HTTPHeaderMatch{Name: gatewayv1.HTTPHeaderName(name), Value: "v"})
+		}
+		return matches
+	}
+	get := ptr.To(gatewayv1.HTTPMethodGet)
+
+	tests := []struct {
+		name   string
+		routes []*routev3.Route
+		want   []string
+	}{
+		{
+			name: "method beats headers",
+			routes: []*routev3.Route{
+				route("headers", gatewayv1.HTTPRouteMatch{Path: prefix("/v1"), Headers: headers("a", "b")}),
+				route("method", gatewayv1.HTTPRouteMatch{Path: prefix("/v1"), Method: get}),
+			},
+			want: []string{"method", "headers"},
+		},
+		{
+			name: "method does not count as a header",
+			routes: []*routev3.Route{
+				route("method-one-header", gatewayv1.HTTPRouteMatch{Path: prefix("/v1"), Method: get, Headers: headers("a")}),
+				route("method-two-headers", gatewayv1.HTTPRouteMatch{Path: prefix("/v1"), Method: get, Headers: headers("a", "b")}),
+				route("method", gatewayv1.HTTPRouteMatch{Path: prefix("/v1"), Method: get}),
+			},
+			want: []string{"method-two-headers", "method-one-header", "method"},
+		},
+		{
+			name: "longer prefix beats method",
+			routes: []*routev3.Route{
+				route("method", gatewayv1.HTTPRouteMatch{Path: prefix("/v1"), Method: get}),
+				route("longer", gatewayv1.HTTPRouteMatch{Path: prefix("/v1/chat")}),
+			},
+			want: []string{"longer", "method"},
+		},
+		{
+			name: "method beats query parameters",
+			routes: []*routev3.Route{
+				route("query", gatewayv1.HTTPRouteMatch{Path: prefix("/v1"), QueryParams: []gatewayv1.HTTPQueryParamMatch{{Name: "stream", Value: "true"}}}),
+				route("method", gatewayv1.HTTPRouteMatch{Path: prefix("/v1"), Method: get}),
+			},
+			want: []string{"method", "query"},
+		},
+		{
+			name: "catch-all comes last, even after a method-only match",
+			routes: []*routev3.Route{
+				route("catch-all", gatewayv1.HTTPRouteMatch{}),
+				route("method", gatewayv1.HTTPRouteMatch{Method: get}),
+				route("exact", gatewayv1.HTTPRouteMatch{Path: &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchExact), Value: ptr.To("/")}}),
+			},
+			want: []string{"exact", "method", "catch-all"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortRoutes(tt.routes)
+			var got []string
+			for _, route := range tt.routes {
+				got = append(got, route.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sortRoutes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchBackendWatchedNamespaces(t *testing.T) {
+	service := func(namespace string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "llm"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+		}
+	}
+	backend := &v0alpha0.XBackendDestination{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "remote"},
+		Spec: v0alpha0.XBackendDestinationSpec{Destination: v0alpha0.BackendDestination{
+			Type:    v0alpha0.BackendTypeService,
+			Ports:   []v0alpha0.BackendPort{{Number: 80}},
+			Service: &v0alpha0.ServiceBackend{Namespace: "models", Name: "llm"},
+		}},
+	}
+	serviceLister := corev1listers.NewServiceLister(newTestIndexer(t, service("default"), service("models")))
+	backendLister := aigatewaylisters.NewXBackendDestinationLister(newTestIndexer(t, backend))
+
+	tests := []struct {
+		name              string
+		backendRef        gatewayv1.BackendRef
+		watchedNamespaces sets.Set[string]
+		wantUnwatched     bool
+	}{
+		{
+			name:       "all namespaces watched",
+			backendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "llm", Namespace: ptr.To(gatewayv1.Namespace("models"))}},
+		},
+		{
+			name:              "service in a watched namespace",
+			backendRef:        gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "llm"}},
+			watchedNamespaces: sets.New("default"),
+		},
+		{
+			name:              "service in an unwatched namespace",
+			backendRef:        gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "llm", Namespace: ptr.To(gatewayv1.Namespace("models"))}},
+			watchedNamespaces: sets.New("default"),
+			wantUnwatched:     true,
+		},
+		{
+			name: "backend pointing to a service in an unwatched namespace",
+			backendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{
+				Group: ptr.To(gatewayv1.Group(v0alpha0.GroupName)),
+				Kind:  ptr.To(gatewayv1.Kind("Backend")),
+				Name:  "remote",
+			}},
+			watchedNamespaces: sets.New("default"),
+			wantUnwatched:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fetchBackend("default", tt.backendRef, backendLister, serviceLister, tt.watchedNamespaces)
+			if !tt.wantUnwatched {
+				if err != nil {
+					t.Fatalf("fetchBackend() error = %v", err)
+				}
+				return
+			}
+			var controllerErr *ControllerError
+			if !errors.As(err, &controllerErr) || !strings.Contains(controllerErr.Message, "namespace models is not watched") {
+				t.Fatalf("fetchBackend() error = %v, want one naming the unwatched namespace", err)
+			}
+			if controllerErr.Reason != string(gatewayv1.RouteReasonBackendNotFound) {
+				t.Errorf("reason = %s, want %s", controllerErr.Reason, gatewayv1.RouteReasonBackendNotFound)
+			}
+		})
+	}
+}
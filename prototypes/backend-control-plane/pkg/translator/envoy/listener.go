@@ -8,6 +8,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/references"
 )
 
 // setListenerCondition is a helper to safely set a condition on a listener's status
@@ -136,6 +138,17 @@ func (t *translator) validateListenerConflicts(gateway *gatewayv1.Gateway) map[g
 				secretNamespace = string(*certRef.Namespace)
 			}
 
+			if !namespaceWatched(t.watchedNamespaces, secretNamespace) {
+				setListenerCondition(listenerConditions, listener.Name, metav1.Condition{
+					Type:    string(gatewayv1.ListenerConditionResolvedRefs),
+					Status:  metav1.ConditionFalse,
+					Reason:  string(gatewayv1.ListenerReasonInvalidCertificateRef),
+					Message: fmt.Sprintf("reference to Secret %s/%s cannot be resolved: namespace %s is not watched by the controller", secretNamespace, certRef.Name, secretNamespace),
+				})
+				break
+			}
+
+			t.recordRef(references.KindSecret, secretNamespace, string(certRef.Name))
 			secret, err := t.secretLister.Secrets(secretNamespace).Get(string(certRef.Name))
 			if err != nil {
 				setListenerCondition(listenerConditions, listener.Name, metav1.Condition{
@@ -0,0 +1,93 @@
+package envoy
+
+import (
+	"fmt"
+
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoyproxytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	envoycache "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+)
+
+// GatewayTranslation is the translation of one of the Gateways that share their proxies.
+type GatewayTranslation struct {
+	Gateway          *gatewayv1.Gateway
+	Resources        map[resourcev3.Type][]envoyproxytypes.Resource
+	ListenerStatuses []gatewayv1.ListenerStatus
+}
+
+// MergeTranslations combines the translations of Gateways that share their proxies into the
+// resources of a single snapshot. Envoy listeners are renamed after their Gateway so that they
+// don't clash.
+//
+// A port can only serve one Gateway: the first translation with a listener on it keeps it, and
+// the listeners of later Gateways on that port are dropped and reported as not accepted with
+// reason PortUnavailable in their ListenerStatuses. Clusters and ClusterLoadAssignments that
+// several Gateways share are included once.
+func MergeTranslations(translations []*GatewayTranslation) map[resourcev3.Type][]envoyproxytypes.Resource {
+	merged := map[resourcev3.Type][]envoyproxytypes.Resource{
+		resourcev3.ListenerType: {},
+		resourcev3.ClusterType:  {},
+		resourcev3.EndpointType: {},
+	}
+	portOwners := map[uint32]types.NamespacedName{}
+	seen := map[resourcev3.Type]sets.Set[string]{
+		resourcev3.ClusterType:  sets.New[string](),
+		resourcev3.EndpointType: sets.New[string](),
+	}
+
+	for _, translation := range translations {
+		gateway := translation.Gateway
+		gatewayKey := types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}
+
+		for _, resource := range translation.Resources[resourcev3.ListenerType] {
+			listener, ok := resource.(*listenerv3.Listener)
+			if !ok {
+				continue
+			}
+			port := listener.GetAddress().GetSocketAddress().GetPortValue()
+			if owner, taken := portOwners[port]; taken {
+				rejectListenersOnPort(translation, port, owner)
+				continue
+			}
+			portOwners[port] = gatewayKey
+			listener.Name = fmt.Sprintf(constants.MergedListenerNameFormat, gateway.Namespace, gateway.Name, port)
+			merged[resourcev3.ListenerType] = append(merged[resourcev3.ListenerType], listener)
+		}
+
+		// Cluster names only depend on the backend, so the Gateways routing to the same backend
+		// produce the same clusters.
+		for _, typ := range []resourcev3.Type{resourcev3.ClusterType, resourcev3.EndpointType} {
+			for _, resource := range translation.Resources[typ] {
+				name := envoycache.GetResourceName(resource)
+				if seen[typ].Has(name) {
+					continue
+				}
+				seen[typ].Insert(name)
+				merged[typ] = append(merged[typ], resource)
+			}
+		}
+	}
+	return merged
+}
+
+// rejectListenersOnPort marks the Gateway's listeners on a port taken by another Gateway as not
+// accepted.
+func rejectListenersOnPort(translation *GatewayTranslation, port uint32, owner types.NamespacedName) {
+	gateway := translation.Gateway
+	for i, listener := range gateway.Spec.Listeners {
+		if uint32(listener.Port) != port || i >= len(translation.ListenerStatuses) {
+			continue
+		}
+		setListenerNotProgrammed(&translation.ListenerStatuses[i], gateway.Generation,
+			metav1.ConditionFalse, gatewayv1.ListenerReasonPortUnavailable,
+			fmt.Sprintf("Port %d is already used by Gateway %s, which shares the proxies of this Gateway", port, owner),
+			"Listener is not programmed because its port is unavailable")
+	}
+}
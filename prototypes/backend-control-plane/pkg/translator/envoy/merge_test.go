@@ -0,0 +1,139 @@
+package envoy
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoyproxytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	envoycache "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// testGatewayTranslation returns the translation of a Gateway with one listener per port, all of
+// them accepted, that routes to the given clusters.
+func testGatewayTranslation(name string, ports []uint32, clusters ...string) *GatewayTranslation {
+	translation := &GatewayTranslation{
+		Gateway: &gatewayv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Generation: 1},
+		},
+		Resources: map[resourcev3.Type][]envoyproxytypes.Resource{},
+	}
+	for _, port := range ports {
+		name := gatewayv1.SectionName(fmt.Sprintf("http-%d", port))
+		translation.Gateway.Spec.Listeners = append(translation.Gateway.Spec.Listeners, gatewayv1.Listener{
+			Name:     name,
+			Protocol: gatewayv1.HTTPProtocolType,
+			Port:     gatewayv1.PortNumber(port),
+		})
+		translation.ListenerStatuses = append(translation.ListenerStatuses, gatewayv1.ListenerStatus{
+			Name: name,
+			Conditions: []metav1.Condition{{
+				Type:   string(gatewayv1.ListenerConditionAccepted),
+				Status: metav1.ConditionTrue,
+				Reason: string(gatewayv1.ListenerReasonAccepted),
+			}},
+		})
+		translation.Resources[resourcev3.ListenerType] = append(translation.Resources[resourcev3.ListenerType], &listenerv3.Listener{
+			Name: "listener",
+			Address: &corev3.Address{Address: &corev3.Address_SocketAddress{SocketAddress: &corev3.SocketAddress{
+				Address:       "0.0.0.0",
+				PortSpecifier: &corev3.SocketAddress_PortValue{PortValue: port},
+			}}},
+		})
+	}
+	for _, cluster := range clusters {
+		translation.Resources[resourcev3.ClusterType] = append(translation.Resources[resourcev3.ClusterType], &clusterv3.Cluster{Name: cluster})
+		translation.Resources[resourcev3.EndpointType] = append(translation.Resources[resourcev3.EndpointType], &endpointv3.ClusterLoadAssignment{ClusterName: cluster})
+	}
+	return translation
+}
+
+func TestMergeTranslations(t *testing.T) {
+	tests := []struct {
+		name          string
+		translations  []*GatewayTranslation
+		wantListeners []string
+		wantClusters  []string
+		// wantRejected lists, per translation, whether each of its listeners is reported as
+		// PortUnavailable.
+		wantRejected [][]bool
+	}{
+		{
+			name:          "no translations",
+			wantListeners: []string{},
+			wantClusters:  []string{},
+		},
+		{
+			name: "distinct ports",
+			translations: []*GatewayTranslation{
+				testGatewayTranslation("a", []uint32{8080}, "service/default/a/80"),
+				testGatewayTranslation("b", []uint32{8081}, "service/default/b/80"),
+			},
+			wantListeners: []string{"default-a-listener-8080", "default-b-listener-8081"},
+			wantClusters:  []string{"service/default/a/80", "service/default/b/80"},
+			wantRejected:  [][]bool{{false}, {false}},
+		},
+		{
+			name: "first gateway keeps a shared port",
+			translations: []*GatewayTranslation{
+				testGatewayTranslation("a", []uint32{8080}),
+				testGatewayTranslation("b", []uint32{8080, 8081}),
+			},
+			wantListeners: []string{"default-a-listener-8080", "default-b-listener-8081"},
+			wantClusters:  []string{},
+			wantRejected:  [][]bool{{false}, {true, false}},
+		},
+		{
+			name: "shared clusters are included once",
+			translations: []*GatewayTranslation{
+				testGatewayTranslation("a", []uint32{8080}, "service/default/shared/80", "service/default/a/80"),
+				testGatewayTranslation("b", []uint32{8081}, "service/default/shared/80"),
+			},
+			wantListeners: []string{"default-a-listener-8080", "default-b-listener-8081"},
+			wantClusters:  []string{"service/default/shared/80", "service/default/a/80"},
+			wantRejected:  [][]bool{{false}, {false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := MergeTranslations(tt.translations)
+
+			if got := resourceNames(merged[resourcev3.ListenerType]); !slices.Equal(got, tt.wantListeners) {
+				t.Errorf("listeners = %v, want %v", got, tt.wantListeners)
+			}
+			if got := resourceNames(merged[resourcev3.ClusterType]); !slices.Equal(got, tt.wantClusters) {
+				t.Errorf("clusters = %v, want %v", got, tt.wantClusters)
+			}
+			if got := resourceNames(merged[resourcev3.EndpointType]); !slices.Equal(got, tt.wantClusters) {
+				t.Errorf("cluster load assignments = %v, want %v", got, tt.wantClusters)
+			}
+
+			for i, translation := range tt.translations {
+				for j, status := range translation.ListenerStatuses {
+					accepted := meta.FindStatusCondition(status.Conditions, string(gatewayv1.ListenerConditionAccepted))
+					rejected := accepted.Status == metav1.ConditionFalse && accepted.Reason == string(gatewayv1.ListenerReasonPortUnavailable)
+					if rejected != tt.wantRejected[i][j] {
+						t.Errorf("gateway %s listener %d: Accepted = %+v, want rejected %v", translation.Gateway.Name, j, accepted, tt.wantRejected[i][j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func resourceNames(resources []envoyproxytypes.Resource) []string {
+	names := []string{}
+	for _, resource := range resources {
+		names = append(names, envoycache.GetResourceName(resource))
+	}
+	return names
+}
@@ -0,0 +1,106 @@
+package envoy
+
+import (
+	"fmt"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	luav3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/protoconv"
+)
+
+// backendPathRewriteLua rewrites the path of a request, keeping its query string, as configured
+// by the filter context of the weighted cluster it is sent to. The route, and so the cluster, is
+// picked before the filter runs, and rewriting the path does not pick them again.
+//
+// The context either holds a full_path to replace the path with, or a prefix that the route
+// matched and its replacement, neither of them with a trailing slash.
+const backendPathRewriteLua = `
+function envoy_on_request(request_handle)
+  local context = request_handle:filterContext()
+  local headers = request_handle:headers()
+  local path = headers:get(":path")
+  local query = ""
+  local i = string.find(path, "?", 1, true)
+  if i ~= nil then
+    query = string.sub(path, i)
+    path = string.sub(path, 1, i - 1)
+  end
+  if context["full_path"] ~= nil then
+    path = context["full_path"]
+  else
+    path = context["replacement"] .. string.sub(path, string.len(context["prefix"]) + 1)
+    if path == "" then
+      path = "/"
+    end
+  end
+  headers:replace(":path", path .. query)
+end
+`
+
+// backendPathRewriteFilter returns the HTTP filter that applies the path modifiers of
+// backendRef URLRewrite filters.
+func backendPathRewriteFilter() *hcmv3.HttpFilter {
+	return &hcmv3.HttpFilter{
+		Name: constants.BackendPathRewriteFilterName,
+		ConfigType: &hcmv3.HttpFilter_TypedConfig{
+			TypedConfig: protoconv.MessageToAny(&luav3.Lua{
+				SourceCodes: map[string]*corev3.DataSource{
+					constants.BackendPathRewriteSourceName: {
+						Specifier: &corev3.DataSource_InlineString{InlineString: backendPathRewriteLua},
+					},
+				},
+			}),
+		},
+	}
+}
+
+// translateBackendRefPathRewrite enables the backend path rewrite filter on a weighted cluster
+// entry. ReplacePrefixMatch requires the route to match a path prefix, like it does on rules.
+func translateBackendRefPathRewrite(clusterWeight *routev3.WeightedCluster_ClusterWeight, path *gatewayv1.HTTPPathModifier, match gatewayv1.HTTPRouteMatch) error {
+	context := map[string]any{}
+	switch path.Type {
+	case gatewayv1.FullPathHTTPPathModifier:
+		if path.ReplaceFullPath == nil {
+			return fmt.Errorf("URLRewrite path modifier of type %s requires replaceFullPath", path.Type)
+		}
+		context["full_path"] = *path.ReplaceFullPath
+	case gatewayv1.PrefixMatchHTTPPathModifier:
+		if path.ReplacePrefixMatch == nil {
+			return fmt.Errorf("URLRewrite path modifier of type %s requires replacePrefixMatch", path.Type)
+		}
+		if match.Path == nil || match.Path.Type == nil || *match.Path.Type != gatewayv1.PathMatchPathPrefix || match.Path.Value == nil {
+			return fmt.Errorf("URLRewrite path modifier of type %s requires a PathPrefix match", path.Type)
+		}
+		// Paths are matched and replaced by segment, so "/foo" replaced with "/bar/" turns
+		// "/foo/baz" into "/bar/baz", and "/foo" into "/bar".
+		context["prefix"] = strings.TrimSuffix(*match.Path.Value, "/")
+		context["replacement"] = strings.TrimSuffix(*path.ReplacePrefixMatch, "/")
+	default:
+		return fmt.Errorf("unsupported URLRewrite path modifier type %s", path.Type)
+	}
+
+	filterContext, err := structpb.NewStruct(context)
+	if err != nil {
+		return err
+	}
+	perRoute, err := protoconv.MessageToAnyWithError(&luav3.LuaPerRoute{
+		Override:      &luav3.LuaPerRoute_Name{Name: constants.BackendPathRewriteSourceName},
+		FilterContext: filterContext,
+	})
+	if err != nil {
+		return err
+	}
+	if clusterWeight.TypedPerFilterConfig == nil {
+		clusterWeight.TypedPerFilterConfig = map[string]*anypb.Any{}
+	}
+	clusterWeight.TypedPerFilterConfig[constants.BackendPathRewriteFilterName] = perRoute
+	return nil
+}
@@ -0,0 +1,92 @@
+package envoy
+
+import (
+	"testing"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	luav3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/protoconv"
+)
+
+func TestTranslateBackendRefPathRewrite(t *testing.T) {
+	prefixMatch := func(value string) gatewayv1.HTTPRouteMatch {
+		return gatewayv1.HTTPRouteMatch{Path: &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchPathPrefix), Value: ptr.To(value)}}
+	}
+	exactMatch := gatewayv1.HTTPRouteMatch{Path: &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchExact), Value: ptr.To("/v1")}}
+
+	tests := []struct {
+		name        string
+		path        gatewayv1.HTTPPathModifier
+		match       gatewayv1.HTTPRouteMatch
+		wantContext map[string]any
+		wantErr     bool
+	}{
+		{
+			name:        "full path",
+			path:        gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptr.To("/v1/chat/completions")},
+			match:       exactMatch,
+			wantContext: map[string]any{"full_path": "/v1/chat/completions"},
+		},
+		{
+			name:        "prefix trims trailing slashes",
+			path:        gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptr.To("/openai/")},
+			match:       prefixMatch("/llm/"),
+			wantContext: map[string]any{"prefix": "/llm", "replacement": "/openai"},
+		},
+		{
+			name:        "root prefix",
+			path:        gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptr.To("/v2")},
+			match:       prefixMatch("/"),
+			wantContext: map[string]any{"prefix": "", "replacement": "/v2"},
+		},
+		{
+			name:    "prefix without a prefix match",
+			path:    gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptr.To("/v2")},
+			match:   exactMatch,
+			wantErr: true,
+		},
+		{
+			name:    "full path without a value",
+			path:    gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier},
+			match:   exactMatch,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterWeight := &routev3.WeightedCluster_ClusterWeight{Name: "cluster"}
+			err := translateBackendRefPathRewrite(clusterWeight, &tt.path, tt.match)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("translateBackendRefPathRewrite() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(clusterWeight.TypedPerFilterConfig) != 0 {
+					t.Errorf("expected no per-filter config, got %v", clusterWeight.TypedPerFilterConfig)
+				}
+				return
+			}
+
+			perRoute, err := protoconv.UnmarshalAny[luav3.LuaPerRoute](clusterWeight.TypedPerFilterConfig[constants.BackendPathRewriteFilterName])
+			if err != nil {
+				t.Fatalf("failed to unmarshal per-route config: %v", err)
+			}
+			if name := perRoute.GetName(); name != constants.BackendPathRewriteSourceName {
+				t.Errorf("source name = %q, want %q", name, constants.BackendPathRewriteSourceName)
+			}
+			got := perRoute.GetFilterContext().AsMap()
+			if len(got) != len(tt.wantContext) {
+				t.Fatalf("filter context = %v, want %v", got, tt.wantContext)
+			}
+			for k, v := range tt.wantContext {
+				if got[k] != v {
+					t.Errorf("filter context[%q] = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package envoy
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
@@ -11,6 +12,7 @@ import (
 	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -24,19 +26,32 @@ import (
 	gatewayclientset "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
 
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/api/v0alpha0"
 	aigatewaylisters "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/k8s/client/listers/api/v0alpha0"
 	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/references"
 )
 
 // Inspired by https://github.com/kubernetes-sigs/kube-agentic-networking/blob/prototype/pkg/translator/translator.go
 
 type Translator interface {
-	TranslateGatewayAndReferencesToXDS(context.Context, *gatewayv1.Gateway) (map[resourcev3.Type][]envoyproxytypes.Resource, map[types.NamespacedName][]gatewayv1.RouteParentStatus, error)
+	// TranslateGatewayAndReferencesToXDS translates a Gateway and the routes attached to it into xDS resources.
+	// It also returns the status of each of the Gateway's listeners, in the order they are declared, and the
+	// parent statuses of every HTTPRoute that references the Gateway.
+	TranslateGatewayAndReferencesToXDS(context.Context, *gatewayv1.Gateway) (map[resourcev3.Type][]envoyproxytypes.Resource, []gatewayv1.ListenerStatus, map[types.NamespacedName][]gatewayv1.RouteParentStatus, error)
+	// TranslateEndpointsToXDS regenerates the ClusterLoadAssignments of a Gateway that are backed by the
+	// given Service, reusing the clusters computed by the Gateway's last successful translation. It returns
+	// false if the Gateway needs a full translation instead.
+	TranslateEndpointsToXDS(gateway, service types.NamespacedName) ([]envoyproxytypes.Resource, bool)
+	// ForgetGateway drops the state kept for a deleted Gateway.
+	ForgetGateway(types.NamespacedName)
 }
 
 type translator struct {
 	kubeClient    kubernetes.Interface
 	gatewayClient gatewayclientset.Interface
+	// controllerName is reported as the controller of the route parent statuses we write.
+	controllerName gatewayv1.GatewayController
 
 	namespaceLister     corev1listers.NamespaceLister
 	serviceLister       corev1listers.ServiceLister
@@ -45,9 +60,21 @@ type translator struct {
 	gatewayLister       gatewaylisters.GatewayLister
 	httprouteLister     gatewaylisters.HTTPRouteLister
 	backendLister       aigatewaylisters.XBackendDestinationLister
+	// watchedNamespaces are the namespaces the listers of namespaced objects see, or nil if they
+	// see every namespace.
+	watchedNamespaces sets.Set[string]
+
+	tracker   *references.Tracker
+	endpoints *endpointIndex
+	// refs and endpointSources collect the objects read and the ClusterLoadAssignments built during
+	// a single translation. They are only set on the per-call copy of the translator made by
+	// TranslateGatewayAndReferencesToXDS.
+	refs            sets.Set[references.Ref]
+	endpointSources []endpointSource
 }
 
 func New(
+	controllerName gatewayv1.GatewayController,
 	kubeClient kubernetes.Interface,
 	gatewayClient gatewayclientset.Interface,
 	namespaceLister corev1listers.NamespaceLister,
@@ -57,8 +84,15 @@ func New(
 	gatewayLister gatewaylisters.GatewayLister,
 	httpRouteLister gatewaylisters.HTTPRouteLister,
 	backendLister aigatewaylisters.XBackendDestinationLister,
+	tracker *references.Tracker,
+	watchNamespaces []string,
 ) Translator {
+	var watchedNamespaces sets.Set[string]
+	if len(watchNamespaces) > 0 && !slices.Contains(watchNamespaces, metav1.NamespaceAll) {
+		watchedNamespaces = sets.New(watchNamespaces...)
+	}
 	return &translator{
+		controllerName:      controllerName,
 		kubeClient:          kubeClient,
 		gatewayClient:       gatewayClient,
 		namespaceLister:     namespaceLister,
@@ -68,6 +102,9 @@ func New(
 		gatewayLister:       gatewayLister,
 		httprouteLister:     httpRouteLister,
 		backendLister:       backendLister,
+		watchedNamespaces:   watchedNamespaces,
+		tracker:             tracker,
+		endpoints:           newEndpointIndex(),
 	}
 }
 
@@ -77,18 +114,70 @@ var (
 	)
 )
 
-func (t *translator) TranslateGatewayAndReferencesToXDS(ctx context.Context, gateway *gatewayv1.Gateway) (map[resourcev3.Type][]envoyproxytypes.Resource, map[types.NamespacedName][]gatewayv1.RouteParentStatus, error) {
+func (t *translator) TranslateGatewayAndReferencesToXDS(ctx context.Context, gateway *gatewayv1.Gateway) (map[resourcev3.Type][]envoyproxytypes.Resource, []gatewayv1.ListenerStatus, map[types.NamespacedName][]gatewayv1.RouteParentStatus, error) {
+	// Translate with a copy of the translator that records every object it reads, and hand
+	// those over to the tracker even if translation fails, so that fixing a broken reference
+	// re-triggers the Gateway.
+	gatewayKey := types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}
+	t = t.forTranslation()
+	defer t.tracker.Set(gatewayKey, t.refs)
+
 	httpRoutesByListener, httpRouteStatuses, err := t.gatherRoutesAndParentStatusesForGateway(ctx, gateway)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
-	xdsResources, _, err := t.buildXDSFromGatewayAndRoutes(gateway, httpRoutesByListener, httpRouteStatuses)
+	xdsResources, listenerStatuses, err := t.buildXDSFromGatewayAndRoutes(gateway, httpRoutesByListener, httpRouteStatuses)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
+	t.endpoints.set(gatewayKey, t.endpointSources)
 
-	return xdsResources, httpRouteStatuses, nil
+	return xdsResources, listenerStatuses, httpRouteStatuses, nil
+}
+
+func (t *translator) forTranslation() *translator {
+	tc := *t
+	tc.refs = sets.New[references.Ref]()
+	tc.endpointSources = nil
+	return &tc
+}
+
+// recordRef notes that the current translation read (or tried to read) the given object.
+func (t *translator) recordRef(kind references.Kind, namespace, name string) {
+	if t.refs == nil {
+		return
+	}
+	t.refs.Insert(references.Ref{Kind: kind, Namespace: namespace, Name: name})
+}
+
+// recordBackendRefs records the Services and Backends referenced by an HTTPRoute, whether or
+// not they currently exist.
+func (t *translator) recordBackendRefs(route *gatewayv1.HTTPRoute) {
+	for _, rule := range route.Spec.Rules {
+		for _, backendRef := range rule.BackendRefs {
+			namespace := route.Namespace
+			if backendRef.Namespace != nil {
+				namespace = string(*backendRef.Namespace)
+			}
+			kind := "Service"
+			if backendRef.Kind != nil {
+				kind = string(*backendRef.Kind)
+			}
+			switch kind {
+			case "Service":
+				t.recordRef(references.KindService, namespace, string(backendRef.Name))
+			case "Backend":
+				t.recordRef(references.KindBackend, namespace, string(backendRef.Name))
+				// Service-typed Backends also depend on the Service they point at.
+				backend, err := t.backendLister.XBackendDestinations(namespace).Get(string(backendRef.Name))
+				if err == nil && backend.Spec.Destination.Type == v0alpha0.BackendTypeService && backend.Spec.Destination.Service != nil {
+					service := serviceBackendKey(backend.Spec.Destination.Service, namespace)
+					t.recordRef(references.KindService, service.Namespace, service.Name)
+				}
+			}
+		}
+	}
 }
 
 func (t *translator) gatherRoutesAndParentStatusesForGateway(ctx context.Context, gateway *gatewayv1.Gateway) (map[gatewayv1.SectionName][]*gatewayv1.HTTPRoute, map[types.NamespacedName][]gatewayv1.RouteParentStatus, error) {
@@ -194,6 +283,10 @@ func (t *translator) validateHTTPRoute(
 
 			if sectionNameMatches && portMatches {
 				// The listener matches the ref. Now check if the listener's policy (e.g., hostname) allows it.
+				if listener.AllowedRoutes != nil && listener.AllowedRoutes.Namespaces != nil &&
+					listener.AllowedRoutes.Namespaces.From != nil && *listener.AllowedRoutes.Namespaces.From == gatewayv1.NamespacesFromSelector {
+					t.recordRef(references.KindNamespace, "", httpRoute.Namespace)
+				}
 				if !isAllowedByListener(gateway, listener, httpRoute, t.namespaceLister) {
 					rejectionReason = gatewayv1.RouteReasonNotAllowedByListeners
 					continue
@@ -209,7 +302,7 @@ func (t *translator) validateHTTPRoute(
 		// --- Build the final status for this ParentRef ---
 		status := gatewayv1.RouteParentStatus{
 			ParentRef:      parentRef,
-			ControllerName: constants.EnvoyControllerName,
+			ControllerName: t.controllerName,
 			Conditions:     []metav1.Condition{},
 		}
 
@@ -261,20 +354,22 @@ func (t *translator) buildEDSResources(allBackends []RouteBackend) ([]envoyproxy
 			continue
 		}
 
-		// Determine the ports to create EDS for
-		var ports []uint32
-		if len(backend.Ports) > 0 {
-			for _, port := range backend.Ports {
-				ports = append(ports, port.Number)
-			}
-		} else {
+		if len(backend.Ports) == 0 {
 			return nil, fmt.Errorf("backend %s has no ports defined", backend.String())
 		}
 
-		for _, port := range ports {
-			eds, err := t.generateEDSFromService(backend.ClusterName(), backend.Source.Namespace, port)
+		for _, port := range backend.Ports {
+			source := endpointSource{
+				clusterName: fmt.Sprintf(constants.ClusterNameFormat, backend.Source.Namespace, backend.ClusterName(), port.Number),
+				service:     backend.Service,
+				portName:    port.Name,
+				targetPort:  port.TargetPort,
+			}
+			t.endpointSources = append(t.endpointSources, source)
+
+			eds, err := t.generateEDSFromService(source)
 			if err != nil {
-				klog.Errorf("Failed to generate EDS for backend %s port %d: %v", backend.String(), port, err)
+				klog.Errorf("Failed to generate EDS for backend %s port %d: %v", backend.String(), port.Number, err)
 				continue
 			}
 			edsResources = append(edsResources, eds)
@@ -376,18 +471,50 @@ func (t *translator) buildEnvoyListenerForPort(
 			continue // Skip invalid or conflicted listeners
 		}
 
+		acceptedCondition := metav1.Condition{
+			Type:               string(gatewayv1.ListenerConditionAccepted),
+			Status:             metav1.ConditionTrue,
+			Reason:             string(gatewayv1.ListenerReasonAccepted),
+			Message:            "Listener is valid",
+			ObservedGeneration: gateway.Generation,
+		}
+
 		// Now translate the listener into an Envoy route if the protocol is valid (e.g. HTTP/HTTPS/GRPC)
 		switch listener.Protocol {
 		case gatewayv1.HTTPProtocolType, gatewayv1.HTTPSProtocolType:
 			for _, route := range routesByListener[listener.Name] {
-				routes, allValidBackends, resolvedRefsCondition := translateHTTPRouteToEnvoyRoutes(route, t.serviceLister, t.backendLister)
+				t.recordBackendRefs(route)
+				routes, allValidBackends, resolvedRefsCondition, notAcceptedErr := translateHTTPRouteToEnvoyRoutes(route, t.serviceLister, t.backendLister, t.watchedNamespaces)
+				key := types.NamespacedName{Name: route.Name, Namespace: route.Namespace}
+				currentParentStatuses := parentStatuses[key]
+
+				// A route using features we cannot support is not accepted by any of our parents.
+				// Its references are still reported. Unless only some of its backendRef filters
+				// can't be applied, none of its rules are programmed.
+				if notAcceptedErr != nil {
+					for i := range currentParentStatuses {
+						if !meta.IsStatusConditionTrue(currentParentStatuses[i].Conditions, string(gatewayv1.RouteConditionAccepted)) {
+							continue
+						}
+						meta.SetStatusCondition(&currentParentStatuses[i].Conditions, metav1.Condition{
+							Type:               string(gatewayv1.RouteConditionAccepted),
+							Status:             metav1.ConditionFalse,
+							Reason:             notAcceptedErr.Reason,
+							Message:            notAcceptedErr.Message,
+							ObservedGeneration: route.Generation,
+						})
+						meta.SetStatusCondition(&currentParentStatuses[i].Conditions, resolvedRefsCondition)
+					}
+					parentStatuses[key] = currentParentStatuses
+					if routes == nil {
+						continue
+					}
+				}
 
 				// Track backends for EDS generation
 				allBackendsForListener = append(allBackendsForListener, allValidBackends...)
 
 				// Update the route status with ResolvedRefs condition
-				key := types.NamespacedName{Name: route.Name, Namespace: route.Namespace}
-				currentParentStatuses := parentStatuses[key]
 				for i := range currentParentStatuses {
 					// Only add the ResolvedRefs condition if the parent was Accepted.
 					if meta.IsStatusConditionTrue(currentParentStatuses[i].Conditions, string(gatewayv1.RouteConditionAccepted)) {
@@ -437,6 +564,10 @@ func (t *translator) buildEnvoyListenerForPort(
 			routeConfig := &routev3.RouteConfiguration{
 				Name:         fmt.Sprintf("listener_%s_routes", listener.Name),
 				VirtualHosts: allVirtualHosts,
+				// Inlined route configs have their clusters validated by default, which would make
+				// Envoy reject the whole listener over the undefined cluster invalid backendRefs are
+				// sent to, instead of answering their requests with a 500.
+				ValidateClusters: wrapperspb.Bool(false),
 			}
 
 			filterChain, err := t.translateListenerToFilterChain(gateway, listener, routeConfig)
@@ -461,16 +592,20 @@ func (t *translator) buildEnvoyListenerForPort(
 
 		default:
 			klog.Warningf("Unsupported listener protocol %s for routing on Gateway %s", listener.Protocol, types.NamespacedName{Name: gateway.Name, Namespace: gateway.Namespace}.String())
+			acceptedCondition.Status = metav1.ConditionFalse
+			acceptedCondition.Reason = string(gatewayv1.ListenerReasonUnsupportedProtocol)
+			acceptedCondition.Message = fmt.Sprintf("Protocol %s is not supported", listener.Protocol)
+			meta.SetStatusCondition(&listenerStatus.Conditions, metav1.Condition{
+				Type:               string(gatewayv1.ListenerConditionProgrammed),
+				Status:             metav1.ConditionFalse,
+				Reason:             string(gatewayv1.ListenerReasonInvalid),
+				Message:            fmt.Sprintf("Protocol %s is not supported", listener.Protocol),
+				ObservedGeneration: gateway.Generation,
+			})
 		}
 
 		listenerStatus.AttachedRoutes = attachedRoutes
-		meta.SetStatusCondition(&listenerStatus.Conditions, metav1.Condition{
-			Type:               string(gatewayv1.ListenerConditionAccepted),
-			Status:             metav1.ConditionTrue,
-			Reason:             string(gatewayv1.ListenerReasonAccepted),
-			Message:            "Listener is valid",
-			ObservedGeneration: gateway.Generation,
-		})
+		meta.SetStatusCondition(&listenerStatus.Conditions, acceptedCondition)
 		allListenerStatuses[listener.Name] = *listenerStatus
 		listenerStatuses = append(listenerStatuses, *listenerStatus)
 	}
@@ -512,9 +647,12 @@ func (t *translator) validateListener(
 	listenerStatus := gatewayv1.ListenerStatus{
 		Name:           gatewayv1.SectionName(listener.Name),
 		SupportedKinds: []gatewayv1.RouteGroupKind{},
-		Conditions:     listenerConflictConditions[listener.Name],
+		Conditions:     slices.Clone(listenerConflictConditions[listener.Name]),
 		AttachedRoutes: 0,
 	}
+	for i := range listenerStatus.Conditions {
+		listenerStatus.Conditions[i].ObservedGeneration = observedGeneration
+	}
 	supportedKinds, allKindsValid := getSupportedKinds(listener)
 	listenerStatus.SupportedKinds = supportedKinds
 
@@ -526,17 +664,29 @@ func (t *translator) validateListener(
 			Message:            "Invalid route kinds specified in allowedRoutes",
 			ObservedGeneration: observedGeneration,
 		})
+		setListenerNotProgrammed(&listenerStatus, observedGeneration, metav1.ConditionTrue, gatewayv1.ListenerReasonAccepted, "Listener is valid", "Listener has invalid route kinds")
 		allListenerStatuses[listener.Name] = listenerStatus
-		return nil, false
+		return &listenerStatus, false
 	}
 
 	isConflicted := meta.IsStatusConditionTrue(listenerStatus.Conditions, string(gatewayv1.ListenerConditionConflicted))
 	// If the listener is conflicted set its status and skip Envoy config generation.
 	if isConflicted {
+		conflicted := meta.FindStatusCondition(listenerStatus.Conditions, string(gatewayv1.ListenerConditionConflicted))
+		setListenerNotProgrammed(&listenerStatus, observedGeneration, metav1.ConditionFalse, gatewayv1.ListenerConditionReason(conflicted.Reason), conflicted.Message, "Listener is conflicted")
 		allListenerStatuses[listener.Name] = listenerStatus
-		return nil, false
+		return &listenerStatus, false
 	}
 
+	// Conflicts are reported explicitly so users can tell a clean listener from an unevaluated one.
+	meta.SetStatusCondition(&listenerStatus.Conditions, metav1.Condition{
+		Type:               string(gatewayv1.ListenerConditionConflicted),
+		Status:             metav1.ConditionFalse,
+		Reason:             string(gatewayv1.ListenerReasonNoConflicts),
+		Message:            "No conflicts",
+		ObservedGeneration: observedGeneration,
+	})
+
 	// If there are not references issues then set condition to true
 	if !meta.IsStatusConditionFalse(listenerStatus.Conditions, string(gatewayv1.ListenerConditionResolvedRefs)) {
 		meta.SetStatusCondition(&listenerStatus.Conditions, metav1.Condition{
@@ -551,6 +701,25 @@ func (t *translator) validateListener(
 	return &listenerStatus, true
 }
 
+// setListenerNotProgrammed sets the Accepted condition of a listener that is skipped during
+// translation and marks it as not Programmed.
+func setListenerNotProgrammed(listenerStatus *gatewayv1.ListenerStatus, observedGeneration int64, acceptedStatus metav1.ConditionStatus, acceptedReason gatewayv1.ListenerConditionReason, acceptedMessage, programmedMessage string) {
+	meta.SetStatusCondition(&listenerStatus.Conditions, metav1.Condition{
+		Type:               string(gatewayv1.ListenerConditionAccepted),
+		Status:             acceptedStatus,
+		Reason:             string(acceptedReason),
+		Message:            acceptedMessage,
+		ObservedGeneration: observedGeneration,
+	})
+	meta.SetStatusCondition(&listenerStatus.Conditions, metav1.Condition{
+		Type:               string(gatewayv1.ListenerConditionProgrammed),
+		Status:             metav1.ConditionFalse,
+		Reason:             string(gatewayv1.ListenerReasonInvalid),
+		Message:            programmedMessage,
+		ObservedGeneration: observedGeneration,
+	})
+}
+
 func getSupportedKinds(listener gatewayv1.Listener) ([]gatewayv1.RouteGroupKind, bool) {
 	supportedKinds := []gatewayv1.RouteGroupKind{}
 	allKindsValid := true
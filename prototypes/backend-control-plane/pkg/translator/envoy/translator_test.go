@@ -0,0 +1,392 @@
+package envoy
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoycache "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
+
+	aigatewaylisters "sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/backend/k8s/client/listers/api/v0alpha0"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/protoconv"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/references"
+)
+
+func newTestIndexer(t *testing.T, objects ...interface{}) cache.Indexer {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objects {
+		if err := indexer.Add(obj); err != nil {
+			t.Fatalf("failed to add object: %v", err)
+		}
+	}
+	return indexer
+}
+
+// newTestTranslator returns a translator whose listers serve the given Gateway, HTTPRoute and
+// Services.
+func newTestTranslator(t *testing.T, gateway *gatewayv1.Gateway, route *gatewayv1.HTTPRoute, services ...*corev1.Service) Translator {
+	t.Helper()
+	serviceObjects := make([]interface{}, 0, len(services))
+	for _, service := range services {
+		serviceObjects = append(serviceObjects, service)
+	}
+	return New(
+		"sigs.k8s.io/wg-ai-gateway-envoy-controller",
+		nil,
+		nil,
+		corev1listers.NewNamespaceLister(newTestIndexer(t)),
+		corev1listers.NewServiceLister(newTestIndexer(t, serviceObjects...)),
+		corev1listers.NewSecretLister(newTestIndexer(t)),
+		discoverylisters.NewEndpointSliceLister(newTestIndexer(t)),
+		gatewaylisters.NewGatewayLister(newTestIndexer(t, gateway)),
+		gatewaylisters.NewHTTPRouteLister(newTestIndexer(t, route)),
+		aigatewaylisters.NewXBackendDestinationLister(newTestIndexer(t)),
+		references.NewTracker(),
+		nil,
+	)
+}
+
+// TestTranslateInvalidBackendRefSnapshot checks that a route with an invalid backendRef
+// translates to a snapshot Envoy accepts: it must be consistent, and the clusters its route
+// configurations send traffic to must either exist or not be validated.
+func TestTranslateInvalidBackendRefSnapshot(t *testing.T) {
+	gateway := &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gateway"},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: "wg-ai-gateway",
+			Listeners: []gatewayv1.Listener{{
+				Name:     "http",
+				Protocol: gatewayv1.HTTPProtocolType,
+				Port:     8080,
+			}},
+		},
+	}
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "route"},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: []gatewayv1.ParentReference{{Name: "gateway"}},
+			},
+			Rules: []gatewayv1.HTTPRouteRule{{
+				BackendRefs: []gatewayv1.HTTPBackendRef{
+					{BackendRef: gatewayv1.BackendRef{
+						BackendObjectReference: gatewayv1.BackendObjectReference{Name: "valid", Port: ptr.To(gatewayv1.PortNumber(80))},
+						Weight:                 ptr.To(int32(90)),
+					}},
+					{BackendRef: gatewayv1.BackendRef{
+						BackendObjectReference: gatewayv1.BackendObjectReference{Name: "missing", Port: ptr.To(gatewayv1.PortNumber(80))},
+						Weight:                 ptr.To(int32(10)),
+					}},
+				},
+			}},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "valid"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8080)}},
+		},
+	}
+
+	tr := newTestTranslator(t, gateway, route, service)
+
+	resources, _, routeStatuses, err := tr.TranslateGatewayAndReferencesToXDS(context.Background(), gateway)
+	if err != nil {
+		t.Fatalf("TranslateGatewayAndReferencesToXDS() error = %v", err)
+	}
+
+	parents := routeStatuses[types.NamespacedName{Namespace: "default", Name: "route"}]
+	if len(parents) != 1 {
+		t.Fatalf("expected 1 route parent status, got %d", len(parents))
+	}
+	resolvedRefs := meta.FindStatusCondition(parents[0].Conditions, string(gatewayv1.RouteConditionResolvedRefs))
+	if resolvedRefs == nil || resolvedRefs.Status != metav1.ConditionFalse || resolvedRefs.Reason != string(gatewayv1.RouteReasonBackendNotFound) {
+		t.Errorf("expected ResolvedRefs=False/BackendNotFound, got %+v", resolvedRefs)
+	}
+
+	snapshot, err := envoycache.NewSnapshot("1", resources)
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+	if err := snapshot.Consistent(); err != nil {
+		t.Fatalf("snapshot is not consistent: %v", err)
+	}
+
+	clusters := snapshot.GetResources(resourcev3.ClusterType)
+	referencedInvalid := false
+	for _, resource := range resources[resourcev3.ListenerType] {
+		listener := resource.(*listenerv3.Listener)
+		for _, filterChain := range listener.FilterChains {
+			for _, filter := range filterChain.Filters {
+				hcm, err := protoconv.UnmarshalAny[hcmv3.HttpConnectionManager](filter.GetTypedConfig())
+				if err != nil {
+					t.Fatalf("failed to unmarshal HttpConnectionManager: %v", err)
+				}
+				routeConfig := hcm.GetRouteConfig()
+				validated := routeConfig.GetValidateClusters() == nil || routeConfig.GetValidateClusters().GetValue()
+				for _, name := range routedClusters(routeConfig) {
+					if name == constants.InvalidBackendClusterName {
+						referencedInvalid = true
+					}
+					if _, ok := clusters[name]; !ok && validated {
+						t.Errorf("route configuration %s validates its clusters but references undefined cluster %s", routeConfig.GetName(), name)
+					}
+				}
+			}
+		}
+	}
+	if !referencedInvalid {
+		t.Errorf("expected the share of the invalid backendRef to be routed to %s", constants.InvalidBackendClusterName)
+	}
+}
+
+// routedClusters returns the names of the clusters a route configuration sends traffic to.
+func routedClusters(routeConfig *routev3.RouteConfiguration) []string {
+	var names []string
+	for _, vh := range routeConfig.GetVirtualHosts() {
+		for _, route := range vh.GetRoutes() {
+			action := route.GetRoute()
+			if action == nil {
+				continue
+			}
+			if cluster := action.GetCluster(); cluster != "" {
+				names = append(names, cluster)
+			}
+			for _, weighted := range action.GetWeightedClusters().GetClusters() {
+				names = append(names, weighted.GetName())
+			}
+		}
+	}
+	return names
+}
+
+// TestTranslateRejectedRoute checks that a route with values that cannot be supported is not
+// accepted and still reports complete conditions, since the API server rejects status conditions
+// without a status or reason. Only a route whose backendRef filters cannot be applied is still
+// programmed, with the share of those backendRefs sent to the invalid backend cluster.
+func TestTranslateRejectedRoute(t *testing.T) {
+	validRef := gatewayv1.HTTPBackendRef{BackendRef: gatewayv1.BackendRef{
+		BackendObjectReference: gatewayv1.BackendObjectReference{Name: "valid", Port: ptr.To(gatewayv1.PortNumber(80))},
+	}}
+	missingRef := gatewayv1.HTTPBackendRef{BackendRef: gatewayv1.BackendRef{
+		BackendObjectReference: gatewayv1.BackendObjectReference{Name: "missing", Port: ptr.To(gatewayv1.PortNumber(80))},
+	}}
+	otherRef := *validRef.DeepCopy()
+	otherRef.Name = "other"
+	fullPathRewrite := gatewayv1.HTTPURLRewriteFilter{Path: &gatewayv1.HTTPPathModifier{
+		Type:            gatewayv1.FullPathHTTPPathModifier,
+		ReplaceFullPath: ptr.To("/v1/chat/completions"),
+	}}
+	idleTimeout := &gatewayv1.SessionPersistence{IdleTimeout: ptr.To(gatewayv1.Duration("10m"))}
+
+	tests := []struct {
+		name             string
+		rules            []gatewayv1.HTTPRouteRule
+		wantReason       gatewayv1.RouteConditionReason
+		wantResolvedRefs metav1.ConditionStatus
+		wantRefsReason   gatewayv1.RouteConditionReason
+		// wantClusters are the clusters the route is programmed to send traffic to.
+		wantClusters []string
+	}{
+		{
+			name: "unsupported session persistence",
+			rules: []gatewayv1.HTTPRouteRule{{
+				BackendRefs:        []gatewayv1.HTTPBackendRef{validRef},
+				SessionPersistence: idleTimeout,
+			}},
+			wantReason:       gatewayv1.RouteReasonUnsupportedValue,
+			wantResolvedRefs: metav1.ConditionTrue,
+			wantRefsReason:   gatewayv1.RouteReasonResolvedRefs,
+		},
+		{
+			name: "unsupported session persistence with an unresolved ref",
+			rules: []gatewayv1.HTTPRouteRule{
+				{BackendRefs: []gatewayv1.HTTPBackendRef{validRef}, SessionPersistence: idleTimeout},
+				{BackendRefs: []gatewayv1.HTTPBackendRef{missingRef}},
+			},
+			wantReason:       gatewayv1.RouteReasonUnsupportedValue,
+			wantResolvedRefs: metav1.ConditionFalse,
+			wantRefsReason:   gatewayv1.RouteReasonBackendNotFound,
+		},
+		{
+			name: "unsupported method match",
+			rules: []gatewayv1.HTTPRouteRule{{
+				Matches:     []gatewayv1.HTTPRouteMatch{{Method: ptr.To(gatewayv1.HTTPMethodConnect)}, {Method: ptr.To(gatewayv1.HTTPMethodGet)}},
+				BackendRefs: []gatewayv1.HTTPBackendRef{validRef},
+			}},
+			wantReason:       gatewayv1.RouteReasonUnsupportedValue,
+			wantResolvedRefs: metav1.ConditionTrue,
+			wantRefsReason:   gatewayv1.RouteReasonResolvedRefs,
+		},
+		{
+			name: "session persistence across weighted backendRefs",
+			rules: []gatewayv1.HTTPRouteRule{{
+				BackendRefs:        []gatewayv1.HTTPBackendRef{validRef, otherRef},
+				SessionPersistence: &gatewayv1.SessionPersistence{},
+			}},
+			wantReason:       gatewayv1.RouteReasonUnsupportedValue,
+			wantResolvedRefs: metav1.ConditionTrue,
+			wantRefsReason:   gatewayv1.RouteReasonResolvedRefs,
+		},
+		{
+			name: "incompatible backendRef filters",
+			rules: []gatewayv1.HTTPRouteRule{{
+				Filters: []gatewayv1.HTTPRouteFilter{{Type: gatewayv1.HTTPRouteFilterURLRewrite, URLRewrite: &fullPathRewrite}},
+				BackendRefs: []gatewayv1.HTTPBackendRef{validRef, {
+					BackendRef: otherRef.BackendRef,
+					Filters:    []gatewayv1.HTTPRouteFilter{{Type: gatewayv1.HTTPRouteFilterURLRewrite, URLRewrite: &fullPathRewrite}},
+				}},
+			}},
+			wantReason:       gatewayv1.RouteReasonIncompatibleFilters,
+			wantResolvedRefs: metav1.ConditionTrue,
+			wantRefsReason:   gatewayv1.RouteReasonResolvedRefs,
+			wantClusters:     []string{"default-synsvc-valid-80", constants.InvalidBackendClusterName},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := &gatewayv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gateway"},
+				Spec: gatewayv1.GatewaySpec{
+					GatewayClassName: "wg-ai-gateway",
+					Listeners:        []gatewayv1.Listener{{Name: "http", Protocol: gatewayv1.HTTPProtocolType, Port: 8080}},
+				},
+			}
+			route := &gatewayv1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "route"},
+				Spec: gatewayv1.HTTPRouteSpec{
+					CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{{Name: "gateway"}}},
+					Rules:           tt.rules,
+				},
+			}
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "valid"},
+				Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+			}
+			other := service.DeepCopy()
+			other.Name = "other"
+
+			tr := newTestTranslator(t, gateway, route, service, other)
+			resources, _, routeStatuses, err := tr.TranslateGatewayAndReferencesToXDS(context.Background(), gateway)
+			if err != nil {
+				t.Fatalf("TranslateGatewayAndReferencesToXDS() error = %v", err)
+			}
+
+			parents := routeStatuses[types.NamespacedName{Namespace: "default", Name: "route"}]
+			if len(parents) != 1 {
+				t.Fatalf("expected 1 route parent status, got %d", len(parents))
+			}
+			for _, condition := range parents[0].Conditions {
+				if condition.Status == "" || condition.Reason == "" {
+					t.Errorf("condition %s has an empty status or reason: %+v", condition.Type, condition)
+				}
+			}
+			accepted := meta.FindStatusCondition(parents[0].Conditions, string(gatewayv1.RouteConditionAccepted))
+			if accepted == nil || accepted.Status != metav1.ConditionFalse || accepted.Reason != string(tt.wantReason) {
+				t.Errorf("expected Accepted=False/%s, got %+v", tt.wantReason, accepted)
+			}
+			resolvedRefs := meta.FindStatusCondition(parents[0].Conditions, string(gatewayv1.RouteConditionResolvedRefs))
+			if resolvedRefs == nil || resolvedRefs.Status != tt.wantResolvedRefs || resolvedRefs.Reason != string(tt.wantRefsReason) {
+				t.Errorf("expected ResolvedRefs=%s/%s, got %+v", tt.wantResolvedRefs, tt.wantRefsReason, resolvedRefs)
+			}
+
+			var clusters []string
+			for _, resource := range resources[resourcev3.ListenerType] {
+				for _, filterChain := range resource.(*listenerv3.Listener).FilterChains {
+					for _, filter := range filterChain.Filters {
+						hcm, err := protoconv.UnmarshalAny[hcmv3.HttpConnectionManager](filter.GetTypedConfig())
+						if err != nil {
+							t.Fatalf("failed to unmarshal HttpConnectionManager: %v", err)
+						}
+						clusters = append(clusters, routedClusters(hcm.GetRouteConfig())...)
+					}
+				}
+			}
+			if !slices.Equal(clusters, tt.wantClusters) {
+				t.Errorf("route is programmed to send traffic to %v, want %v", clusters, tt.wantClusters)
+			}
+		})
+	}
+}
+
+// TestTranslateZeroWeightBackendRefs checks that a rule whose backendRefs all have a weight of 0
+// answers with a 500 without failing the ResolvedRefs condition.
+func TestTranslateZeroWeightBackendRefs(t *testing.T) {
+	gateway := &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gateway"},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: "wg-ai-gateway",
+			Listeners:        []gatewayv1.Listener{{Name: "http", Protocol: gatewayv1.HTTPProtocolType, Port: 8080}},
+		},
+	}
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "route"},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{{Name: "gateway"}}},
+			Rules: []gatewayv1.HTTPRouteRule{{
+				BackendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: gatewayv1.BackendRef{
+					BackendObjectReference: gatewayv1.BackendObjectReference{Name: "valid", Port: ptr.To(gatewayv1.PortNumber(80))},
+					Weight:                 ptr.To(int32(0)),
+				}}},
+			}},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "valid"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+	}
+
+	tr := newTestTranslator(t, gateway, route, service)
+	resources, _, routeStatuses, err := tr.TranslateGatewayAndReferencesToXDS(context.Background(), gateway)
+	if err != nil {
+		t.Fatalf("TranslateGatewayAndReferencesToXDS() error = %v", err)
+	}
+
+	parents := routeStatuses[types.NamespacedName{Namespace: "default", Name: "route"}]
+	if len(parents) != 1 {
+		t.Fatalf("expected 1 route parent status, got %d", len(parents))
+	}
+	for _, conditionType := range []gatewayv1.RouteConditionType{gatewayv1.RouteConditionAccepted, gatewayv1.RouteConditionResolvedRefs} {
+		if !meta.IsStatusConditionTrue(parents[0].Conditions, string(conditionType)) {
+			t.Errorf("expected %s=True, got %+v", conditionType, meta.FindStatusCondition(parents[0].Conditions, string(conditionType)))
+		}
+	}
+
+	var statuses []uint32
+	for _, resource := range resources[resourcev3.ListenerType] {
+		for _, filterChain := range resource.(*listenerv3.Listener).FilterChains {
+			for _, filter := range filterChain.Filters {
+				hcm, err := protoconv.UnmarshalAny[hcmv3.HttpConnectionManager](filter.GetTypedConfig())
+				if err != nil {
+					t.Fatalf("failed to unmarshal HttpConnectionManager: %v", err)
+				}
+				for _, vh := range hcm.GetRouteConfig().GetVirtualHosts() {
+					for _, r := range vh.GetRoutes() {
+						statuses = append(statuses, r.GetDirectResponse().GetStatus())
+					}
+				}
+			}
+		}
+	}
+	if !slices.Equal(statuses, []uint32{500}) {
+		t.Errorf("expected a single route answering with a 500, got direct response statuses %v", statuses)
+	}
+}
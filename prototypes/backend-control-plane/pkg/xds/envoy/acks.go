@@ -0,0 +1,85 @@
+package envoy
+
+import (
+	"sync"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+)
+
+// AckHandler is called when a proxy ACKs a response. cluster is the xDS cluster of the proxy,
+// which is the namespace/name of its Gateway.
+type AckHandler func(nodeID, cluster string)
+
+// NackHandler is called when a proxy rejects a response of the given type URL, with the error
+// the proxy reported.
+type NackHandler func(nodeID, cluster, typeURL, message string)
+
+// ackTracker records the versions that the proxies connected to this server have ACKed.
+type ackTracker struct {
+	mu sync.Mutex
+	// streams maps the ID of each open stream to the node that opened it.
+	streams map[int64]string
+	// acked maps a node ID to the last version it ACKed for each type URL.
+	acked map[string]map[string]string
+}
+
+func newAckTracker() *ackTracker {
+	return &ackTracker{
+		streams: map[int64]string{},
+		acked:   map[string]map[string]string{},
+	}
+}
+
+// request records a request received on a stream. It returns true if the request ACKs a response.
+func (t *ackTracker) request(streamID int64, node *corev3.Node, typeURL, version, responseNonce string, rejected bool) bool {
+	if node == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.streams[streamID] = node.GetId()
+	if responseNonce == "" || rejected {
+		return false
+	}
+	if t.acked[node.GetId()] == nil {
+		t.acked[node.GetId()] = map[string]string{}
+	}
+	t.acked[node.GetId()][typeURL] = version
+	return true
+}
+
+// closed forgets a stream, and the ACKed versions of its node once the node has no open stream left.
+func (t *ackTracker) closed(streamID int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	nodeID, ok := t.streams[streamID]
+	if !ok {
+		return
+	}
+	delete(t.streams, streamID)
+	for _, other := range t.streams {
+		if other == nodeID {
+			return
+		}
+	}
+	delete(t.acked, nodeID)
+}
+
+// ackedVersion returns the last version the node ACKed for the type URL, and whether the node
+// has any stream open to this server.
+func (t *ackTracker) ackedVersion(nodeID, typeURL string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	connected := false
+	for _, other := range t.streams {
+		if other == nodeID {
+			connected = true
+			break
+		}
+	}
+	return t.acked[nodeID][typeURL], connected
+}
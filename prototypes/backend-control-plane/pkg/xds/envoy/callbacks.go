@@ -7,14 +7,21 @@ import (
 	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	xdsserver "github.com/envoyproxy/go-control-plane/pkg/server/v3"
 	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/metrics"
 )
 
 var _ xdsserver.Callbacks = &callbacks{}
 
-type callbacks struct{}
+type callbacks struct {
+	acks   *ackTracker
+	onAck  AckHandler
+	onNack NackHandler
+}
 
 func (cb *callbacks) OnStreamOpen(ctx context.Context, id int64, typ string) error {
 	klog.V(5).Infof("xDS stream %d opened for type %s", id, typ)
+	metrics.XDSStreams.Inc()
 	return nil
 }
 
@@ -24,10 +31,19 @@ func (cb *callbacks) OnStreamClosed(id int64, node *corev3.Node) {
 		nodeID = node.GetId()
 	}
 	klog.V(5).Infof("xDS stream %d closed for node %s", id, nodeID)
+	metrics.XDSStreams.Dec()
+	cb.acks.closed(id)
 }
 
 func (cb *callbacks) OnStreamRequest(id int64, req *discoveryv3.DiscoveryRequest) error {
 	klog.V(5).Infof("xDS stream %d received request for type %s from node %s", id, req.TypeUrl, req.Node.GetId())
+	recordAck(req.TypeUrl, req.ResponseNonce, req.ErrorDetail != nil)
+	if cb.acks.request(id, req.Node, req.TypeUrl, req.VersionInfo, req.ResponseNonce, req.ErrorDetail != nil) && cb.onAck != nil {
+		cb.onAck(req.Node.GetId(), req.Node.GetCluster())
+	}
+	if req.ResponseNonce != "" && req.ErrorDetail != nil && cb.onNack != nil {
+		cb.onNack(req.Node.GetId(), req.Node.GetCluster(), req.TypeUrl, req.ErrorDetail.GetMessage())
+	}
 	return nil
 }
 
@@ -45,14 +61,31 @@ func (cb *callbacks) OnFetchResponse(req *discoveryv3.DiscoveryRequest, resp *di
 }
 
 func (cb *callbacks) OnStreamDeltaRequest(id int64, req *discoveryv3.DeltaDiscoveryRequest) error {
+	recordAck(req.TypeUrl, req.ResponseNonce, req.ErrorDetail != nil)
 	return nil
 }
 
 func (cb *callbacks) OnStreamDeltaResponse(id int64, req *discoveryv3.DeltaDiscoveryRequest, resp *discoveryv3.DeltaDiscoveryResponse) {
 }
 
-func (cb *callbacks) OnDeltaStreamClosed(int64, *corev3.Node) {}
+func (cb *callbacks) OnDeltaStreamClosed(int64, *corev3.Node) {
+	metrics.XDSStreams.Dec()
+}
 
 func (cb *callbacks) OnDeltaStreamOpen(context.Context, int64, string) error {
+	metrics.XDSStreams.Inc()
 	return nil
 }
+
+// recordAck counts a request that answers an earlier response, which is the case when it carries
+// that response's nonce, as an ACK, or as a NACK if the proxy reported an error.
+func recordAck(typeURL, responseNonce string, rejected bool) {
+	if responseNonce == "" {
+		return
+	}
+	if rejected {
+		metrics.XDSNacks.WithLabelValues(typeURL).Inc()
+		return
+	}
+	metrics.XDSAcks.WithLabelValues(typeURL).Inc()
+}
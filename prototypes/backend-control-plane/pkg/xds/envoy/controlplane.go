@@ -23,6 +23,7 @@ import (
 	"math"
 	"net"
 	"strconv"
+	"sync"
 	"sync/atomic"
 
 	envoy_service_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/service/cluster/v3"
@@ -37,16 +38,30 @@ import (
 	xdsserver "github.com/envoyproxy/go-control-plane/pkg/server/v3"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/proto"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/constants"
+	"sigs.k8s.io/wg-ai-gateway/prototypes/backend-control-plane/pkg/metrics"
 )
 
 type ControlPlane interface {
 	PushXDS(context.Context, string, map[resourcev3.Type][]envoyproxytypes.Resource) error
+	// PushEndpoints replaces ClusterLoadAssignments in the node's current snapshot, leaving every
+	// other resource type at its current version so that Envoy only receives an EDS update.
+	PushEndpoints(context.Context, string, []envoyproxytypes.Resource) error
+	// ClearSnapshot evicts the node's snapshot, so that a proxy that still connects with that
+	// node ID receives no configuration.
+	ClearSnapshot(string)
 	Run(context.Context) error
+	// Ready returns an error until the xDS server is listening.
+	Ready() error
+	// SnapshotAcked returns whether the proxies of the node have ACKed every resource type of
+	// its current snapshot, and whether any of them is connected to this server at all.
+	SnapshotAcked(string) (acked bool, connected bool)
 }
 
 type controlPlane struct {
@@ -54,6 +69,11 @@ type controlPlane struct {
 	cache  envoycache.SnapshotCache
 	// versionCounter is used to generate monotonically increasing version numbers for snapshots
 	versionCounter atomic.Uint64
+	// mu serializes snapshot updates, since PushEndpoints reads and then rewrites a node's snapshot.
+	mu sync.Mutex
+	// serving is set once the xDS server is listening.
+	serving atomic.Bool
+	acks    *ackTracker
 }
 
 // slogAdapterForEnvoy adapts *slog.Logger to envoylog.Logger interface
@@ -88,18 +108,24 @@ func (s *slogAdapterForEnvoy) Errorf(format string, args ...any) {
 	}
 }
 
+// NewControlPlane creates the xDS server. onAck and onNack, if not nil, are called whenever a
+// proxy ACKs or rejects a response.
 func NewControlPlane(
 	ctx context.Context,
+	onAck AckHandler,
+	onNack NackHandler,
 ) ControlPlane {
 	baseLogger := slog.Default().With("component", "envoy-controlplane")
 	envoyLoggerAdapter := &slogAdapterForEnvoy{logger: baseLogger}
 
+	acks := newAckTracker()
 	snapshotCache := envoycache.NewSnapshotCache(false, envoycache.IDHash{}, envoyLoggerAdapter)
-	xdsServer := xdsserver.NewServer(ctx, snapshotCache, &callbacks{})
+	xdsServer := xdsserver.NewServer(ctx, snapshotCache, &callbacks{acks: acks, onAck: onAck, onNack: onNack})
 
 	return &controlPlane{
 		server: xdsServer,
 		cache:  snapshotCache,
+		acks:   acks,
 	}
 }
 
@@ -135,10 +161,12 @@ func (cp *controlPlane) Run(ctx context.Context) error {
 	}
 
 	// Start the server
+	cp.serving.Store(true)
 	go func() {
 		if err := grpcServer.Serve(listener); err != nil {
 			klog.Errorln("Envoy xDS server failed:", err)
 		}
+		cp.serving.Store(false)
 	}()
 
 	// Handle graceful shutdown for both servers
@@ -150,12 +178,31 @@ func (cp *controlPlane) Run(ctx context.Context) error {
 	return nil
 }
 
+func (cp *controlPlane) Ready() error {
+	if !cp.serving.Load() {
+		return fmt.Errorf("xDS server is not serving")
+	}
+	return nil
+}
+
 // PushXDS takes the xDS resources and updates the snapshot cache so they are pushed to the appropriate envoy proxies (based on the node ID).
 func (cp *controlPlane) PushXDS(ctx context.Context, nodeID string, resources map[resourcev3.Type][]envoyproxytypes.Resource) error {
 	if nodeID == "" {
 		return fmt.Errorf("nodeID cannot be empty")
 	}
 
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	// Keep the current version if nothing changed, so that proxies are not sent the same
+	// configuration again and keep their ACKs for it.
+	if current, err := cp.cache.GetSnapshot(nodeID); err == nil {
+		if currentSnapshot, ok := current.(*envoycache.Snapshot); ok && sameResources(currentSnapshot, resources) {
+			klog.V(4).InfoS("xDS snapshot unchanged", "nodeID", nodeID)
+			return nil
+		}
+	}
+
 	// Generate a new version for this snapshot
 	// Versions must be distinct and monotonically increasing for proper xDS updates
 	version := strconv.FormatUint(cp.versionCounter.Add(1), 10)
@@ -176,6 +223,8 @@ func (cp *controlPlane) PushXDS(ctx context.Context, nodeID string, resources ma
 		return fmt.Errorf("failed to set snapshot for node %s: %w", nodeID, err)
 	}
 
+	recordSnapshotMetrics(nodeID, version, snapshot)
+
 	slog.Info("Updated xDS snapshot",
 		"nodeID", nodeID,
 		"version", version,
@@ -183,3 +232,131 @@ func (cp *controlPlane) PushXDS(ctx context.Context, nodeID string, resources ma
 
 	return nil
 }
+
+// PushEndpoints updates the ClusterLoadAssignments of a node that already has a snapshot. Only
+// assignments for clusters present in the current snapshot are replaced, and no new version is
+// made if none of them changed.
+func (cp *controlPlane) PushEndpoints(ctx context.Context, nodeID string, endpoints []envoyproxytypes.Resource) error {
+	if nodeID == "" {
+		return fmt.Errorf("nodeID cannot be empty")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	current, err := cp.cache.GetSnapshot(nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot for node %s: %w", nodeID, err)
+	}
+	currentSnapshot, ok := current.(*envoycache.Snapshot)
+	if !ok {
+		return fmt.Errorf("unexpected snapshot type %T for node %s", current, nodeID)
+	}
+
+	// Copy the current resources so the snapshot held by the cache is never mutated.
+	snapshot := &envoycache.Snapshot{Resources: currentSnapshot.Resources}
+	currentEndpoints := currentSnapshot.Resources[envoyproxytypes.Endpoint]
+	items := make(map[string]envoyproxytypes.ResourceWithTTL, len(currentEndpoints.Items))
+	for name, item := range currentEndpoints.Items {
+		items[name] = item
+	}
+
+	updated := 0
+	for _, endpoint := range endpoints {
+		name := envoycache.GetResourceName(endpoint)
+		current, ok := items[name]
+		if !ok {
+			// The cluster is not part of the current snapshot; it will be picked up by the next full push.
+			continue
+		}
+		if proto.Equal(current.Resource, endpoint) {
+			continue
+		}
+		items[name] = envoyproxytypes.ResourceWithTTL{Resource: endpoint}
+		updated++
+	}
+	if updated == 0 {
+		return nil
+	}
+
+	version := strconv.FormatUint(cp.versionCounter.Add(1), 10)
+	snapshot.Resources[envoyproxytypes.Endpoint] = envoycache.Resources{
+		Version: version,
+		Items:   items,
+	}
+
+	if err := snapshot.Consistent(); err != nil {
+		return fmt.Errorf("snapshot for node %s is not consistent: %w", nodeID, err)
+	}
+
+	if err := cp.cache.SetSnapshot(ctx, nodeID, snapshot); err != nil {
+		return fmt.Errorf("failed to set snapshot for node %s: %w", nodeID, err)
+	}
+
+	recordSnapshotMetrics(nodeID, version, snapshot)
+
+	slog.Info("Updated xDS endpoints",
+		"nodeID", nodeID,
+		"version", version,
+		"endpointCount", updated)
+
+	return nil
+}
+
+func (cp *controlPlane) SnapshotAcked(nodeID string) (bool, bool) {
+	current, err := cp.cache.GetSnapshot(nodeID)
+	if err != nil {
+		return false, false
+	}
+	snapshot, ok := current.(*envoycache.Snapshot)
+	if !ok {
+		return false, false
+	}
+
+	acked, connected := true, false
+	for _, typ := range []resourcev3.Type{resourcev3.ListenerType, resourcev3.RouteType, resourcev3.ClusterType, resourcev3.EndpointType} {
+		version, typeConnected := cp.acks.ackedVersion(nodeID, typ)
+		connected = connected || typeConnected
+		// Proxies only subscribe to the types they need, so empty types are never ACKed.
+		if len(snapshot.GetResources(typ)) > 0 && version != snapshot.GetVersion(typ) {
+			acked = false
+		}
+	}
+	return acked && connected, connected
+}
+
+// sameResources returns whether the snapshot holds exactly the given resources.
+func sameResources(snapshot *envoycache.Snapshot, resources map[resourcev3.Type][]envoyproxytypes.Resource) bool {
+	for _, typ := range []resourcev3.Type{resourcev3.ListenerType, resourcev3.RouteType, resourcev3.ClusterType, resourcev3.EndpointType} {
+		current := snapshot.GetResources(typ)
+		if len(current) != len(resources[typ]) {
+			return false
+		}
+		for _, r := range resources[typ] {
+			existing, ok := current[envoycache.GetResourceName(r)]
+			if !ok || !proto.Equal(existing, r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (cp *controlPlane) ClearSnapshot(nodeID string) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	cp.cache.ClearSnapshot(nodeID)
+	metrics.SnapshotVersion.DeleteLabelValues(nodeID)
+	metrics.SnapshotResources.DeletePartialMatch(prometheus.Labels{"node_id": nodeID})
+	klog.InfoS("Cleared xDS snapshot", "nodeID", nodeID)
+}
+
+func recordSnapshotMetrics(nodeID, version string, snapshot *envoycache.Snapshot) {
+	if v, err := strconv.ParseFloat(version, 64); err == nil {
+		metrics.SnapshotVersion.WithLabelValues(nodeID).Set(v)
+	}
+	for _, typ := range []resourcev3.Type{resourcev3.ListenerType, resourcev3.RouteType, resourcev3.ClusterType, resourcev3.EndpointType} {
+		metrics.SnapshotResources.WithLabelValues(nodeID, typ).Set(float64(len(snapshot.GetResources(typ))))
+	}
+}
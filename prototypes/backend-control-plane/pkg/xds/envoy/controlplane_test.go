@@ -0,0 +1,123 @@
+package envoy
+
+import (
+	"context"
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	envoyproxytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	envoycache "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"google.golang.org/protobuf/proto"
+)
+
+func edsCluster(name string) *clusterv3.Cluster {
+	return &clusterv3.Cluster{
+		Name:                 name,
+		ClusterDiscoveryType: &clusterv3.Cluster_Type{Type: clusterv3.Cluster_EDS},
+		EdsClusterConfig: &clusterv3.Cluster_EdsClusterConfig{
+			EdsConfig: &corev3.ConfigSource{ConfigSourceSpecifier: &corev3.ConfigSource_Ads{Ads: &corev3.AggregatedConfigSource{}}},
+		},
+	}
+}
+
+func loadAssignment(cluster string, addresses ...string) *endpointv3.ClusterLoadAssignment {
+	var endpoints []*endpointv3.LbEndpoint
+	for _, address := range addresses {
+		endpoints = append(endpoints, &endpointv3.LbEndpoint{
+			HostIdentifier: &endpointv3.LbEndpoint_Endpoint{Endpoint: &endpointv3.Endpoint{
+				Address: &corev3.Address{Address: &corev3.Address_SocketAddress{SocketAddress: &corev3.SocketAddress{
+					Address:       address,
+					PortSpecifier: &corev3.SocketAddress_PortValue{PortValue: 8080},
+				}}},
+			}},
+		})
+	}
+	return &endpointv3.ClusterLoadAssignment{
+		ClusterName: cluster,
+		Endpoints:   []*endpointv3.LocalityLbEndpoints{{LbEndpoints: endpoints}},
+	}
+}
+
+func currentSnapshot(t *testing.T, cp *controlPlane, nodeID string) *envoycache.Snapshot {
+	t.Helper()
+	current, err := cp.cache.GetSnapshot(nodeID)
+	if err != nil {
+		t.Fatalf("GetSnapshot() error = %v", err)
+	}
+	return current.(*envoycache.Snapshot)
+}
+
+func TestPushEndpoints(t *testing.T) {
+	const nodeID = "envoy-default-gw"
+	ctx := context.Background()
+	cp := NewControlPlane(ctx, nil, nil).(*controlPlane)
+
+	if err := cp.PushEndpoints(ctx, nodeID, []envoyproxytypes.Resource{loadAssignment("a", "10.0.0.1")}); err == nil {
+		t.Errorf("PushEndpoints() without a snapshot succeeded, want an error")
+	}
+
+	err := cp.PushXDS(ctx, nodeID, map[resourcev3.Type][]envoyproxytypes.Resource{
+		resourcev3.ClusterType:  {edsCluster("a"), edsCluster("b")},
+		resourcev3.EndpointType: {loadAssignment("a", "10.0.0.1"), loadAssignment("b", "10.0.0.2")},
+	})
+	if err != nil {
+		t.Fatalf("PushXDS() error = %v", err)
+	}
+	before := currentSnapshot(t, cp, nodeID)
+
+	t.Run("unchanged assignments keep the version", func(t *testing.T) {
+		err := cp.PushEndpoints(ctx, nodeID, []envoyproxytypes.Resource{loadAssignment("a", "10.0.0.1")})
+		if err != nil {
+			t.Fatalf("PushEndpoints() error = %v", err)
+		}
+		if got := currentSnapshot(t, cp, nodeID); got.GetVersion(resourcev3.EndpointType) != before.GetVersion(resourcev3.EndpointType) {
+			t.Errorf("endpoint version = %s, want %s", got.GetVersion(resourcev3.EndpointType), before.GetVersion(resourcev3.EndpointType))
+		}
+	})
+
+	t.Run("clusters missing from the snapshot are skipped", func(t *testing.T) {
+		err := cp.PushEndpoints(ctx, nodeID, []envoyproxytypes.Resource{loadAssignment("c", "10.0.0.3")})
+		if err != nil {
+			t.Fatalf("PushEndpoints() error = %v", err)
+		}
+		got := currentSnapshot(t, cp, nodeID)
+		if got.GetVersion(resourcev3.EndpointType) != before.GetVersion(resourcev3.EndpointType) {
+			t.Errorf("endpoint version = %s, want %s", got.GetVersion(resourcev3.EndpointType), before.GetVersion(resourcev3.EndpointType))
+		}
+		if _, ok := got.GetResources(resourcev3.EndpointType)["c"]; ok {
+			t.Errorf("assignment for cluster c was added to the snapshot")
+		}
+	})
+
+	t.Run("changed assignments only bump the endpoint version", func(t *testing.T) {
+		updated := loadAssignment("a", "10.0.0.1", "10.0.0.4")
+		err := cp.PushEndpoints(ctx, nodeID, []envoyproxytypes.Resource{updated, loadAssignment("c", "10.0.0.3")})
+		if err != nil {
+			t.Fatalf("PushEndpoints() error = %v", err)
+		}
+		got := currentSnapshot(t, cp, nodeID)
+		if got.GetVersion(resourcev3.EndpointType) == before.GetVersion(resourcev3.EndpointType) {
+			t.Errorf("endpoint version was not bumped")
+		}
+		if got.GetVersion(resourcev3.ClusterType) != before.GetVersion(resourcev3.ClusterType) {
+			t.Errorf("cluster version = %s, want %s", got.GetVersion(resourcev3.ClusterType), before.GetVersion(resourcev3.ClusterType))
+		}
+		endpoints := got.GetResources(resourcev3.EndpointType)
+		if !proto.Equal(endpoints["a"], updated) {
+			t.Errorf("assignment for cluster a = %v, want %v", endpoints["a"], updated)
+		}
+		if !proto.Equal(endpoints["b"], loadAssignment("b", "10.0.0.2")) {
+			t.Errorf("assignment for cluster b changed to %v", endpoints["b"])
+		}
+		if _, ok := endpoints["c"]; ok {
+			t.Errorf("assignment for cluster c was added to the snapshot")
+		}
+		// The snapshot held by the cache before the push is left untouched.
+		if !proto.Equal(before.GetResources(resourcev3.EndpointType)["a"], loadAssignment("a", "10.0.0.1")) {
+			t.Errorf("previous snapshot was mutated")
+		}
+	})
+}